@@ -3,29 +3,80 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"net/url"
+	"os"
 	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/taihen/ros-exporter/pkg/config"
 	"github.com/taihen/ros-exporter/pkg/metrics"
 	"github.com/taihen/ros-exporter/pkg/mikrotik"
 )
 
 const defaultUsername = "prometheus"
-const defaultAPIPort = "8728"
+const defaultModuleName = "default"
 
 var (
-	listenAddressFlag = flag.String("web.listen-address", ":9483", "Address to listen on for web interface and telemetry.")
-	metricsPathFlag   = flag.String("web.telemetry-path", "/metrics", "Path under which to expose metrics.")
-	scrapeTimeout     = flag.Duration("scrape.timeout", mikrotik.DefaultTimeout, "Timeout for scraping a target.")
+	listenAddressFlag   = flag.String("web.listen-address", ":9483", "Address to listen on for web interface and telemetry.")
+	metricsPathFlag     = flag.String("web.telemetry-path", "/metrics", "Path under which to expose metrics.")
+	exporterPathFlag    = flag.String("web.exporter-telemetry-path", "/exporter-metrics", "Path under which to expose the exporter's own metrics.")
+	scrapeTimeout       = flag.Duration("scrape.timeout", mikrotik.DefaultTimeout, "Timeout for scraping a target.")
+	scrapeTimeoutOffset = flag.Duration("scrape.timeout-offset", 500*time.Millisecond, "Offset subtracted from the timeout announced by Prometheus in X-Prometheus-Scrape-Timeout-Seconds.")
+	maxConcurrency      = flag.Int("scrape.max-concurrency", mikrotik.DefaultMaxConcurrency, "Maximum number of API commands in flight per target; 1 disables concurrent collection.")
+	configFileFlag      = flag.String("config.file", "", "Path to the YAML configuration file with named modules.")
+	capabilitiesTTLFlag = flag.Duration("capabilities.ttl", mikrotik.DefaultCapabilitiesTTL, "How long the detected RouterOS version and packages of a target are reused before probing again.")
+
+	tlsEnabledFlag            = flag.Bool("tls.enabled", false, "Connect to targets using the API-SSL service (TLS) by default.")
+	tlsCAFileFlag             = flag.String("tls.ca-file", "", "CA certificate bundle used to verify the router certificate.")
+	tlsCertFileFlag           = flag.String("tls.cert-file", "", "Client certificate presented to the router.")
+	tlsKeyFileFlag            = flag.String("tls.key-file", "", "Private key for the client certificate.")
+	tlsServerNameFlag         = flag.String("tls.server-name", "", "Override the server name used to verify the router certificate.")
+	tlsInsecureSkipVerifyFlag = flag.Bool("tls.insecure-skip-verify", false, "Skip verification of the router certificate.")
+
+	poolEnabledFlag     = flag.Bool("pool.enabled", false, "Keep authenticated API sessions open between scrapes.")
+	poolIdleTimeoutFlag = flag.Duration("pool.idle-timeout", 5*time.Minute, "Close pooled API sessions that have been idle for this long.")
+	poolMaxIdleFlag     = flag.Int("pool.max-idle-per-target", 2, "Maximum number of idle pooled API sessions per target.")
+
+	bgpAdvertisementsFlag      = flag.Bool("collector.bgp.advertisements", false, "Count the prefixes advertised to each BGP peer from /routing/bgp/advertisements (can be overridden per module or with the bgp_advertisements parameter). Expensive on routers sending full tables.")
+	firewallCommentIncludeFlag = flag.String("collector.firewall.comment-include", "", "Regular expression selecting the firewall rules to export by comment; rules without a comment have an empty comment (can be overridden per module or with the firewall_comment_include parameter). Empty exports all rules.")
+	addressListIncludeFlag     = flag.String("collector.addresslist.include", "", "Regular expression selecting the firewall address lists to count by name (can be overridden per module or with the addresslist_include parameter). Empty counts all lists.")
+	addressListNamesTTLFlag    = flag.Duration("collector.addresslist.names-ttl", mikrotik.DefaultAddressListNamesTTL, "How long the discovered firewall address list names of a target are reused before listing them again.")
+	dhcpLeasesFlag             = flag.Bool("collector.dhcp.leases", false, "Export one series per DHCP lease (can be overridden per module or with the dhcp_leases parameter). Expensive on routers with many leases.")
+
+	recordDirFlag    = flag.String("record.dir", "", "Record the API replies of every scrape as a fixture file per target in this directory.")
+	recordRedactFlag = flag.String("record.redact", "serials,macs,ips", "Comma separated data to redact from recorded fixtures: serials, macs, ips. Passwords are always redacted.")
 )
 
+var (
+	exporterRegistry = prometheus.NewRegistry()
+	exporterConfig   *config.SafeConfig
+	connectionPool   *mikrotik.Pool
+	recordRedaction  mikrotik.Redaction
+)
+
+// collectorFlags holds the -collector.<name> flag of every registered sub-collector.
+var collectorFlags = func() map[string]*bool {
+	flags := make(map[string]*bool)
+	for _, name := range metrics.CollectorNames() {
+		flags[name] = flag.Bool("collector."+name, metrics.CollectorDefaultEnabled(name),
+			fmt.Sprintf("Enable the %s collector (can be overridden per module or with the collect_%s parameter).", name, name))
+	}
+	return flags
+}()
+
 func main() {
 	flag.Parse()
 
@@ -33,14 +84,72 @@ func main() {
 	log.Printf("Listen Address: %s", *listenAddressFlag)
 	log.Printf("Metrics Path: %s", *metricsPathFlag)
 	log.Printf("Scrape Timeout: %s", *scrapeTimeout)
+	log.Printf("Scrape Timeout Offset: %s", *scrapeTimeoutOffset)
+	log.Printf("Max Concurrency per Target: %d", *maxConcurrency)
 	log.Printf("Default Username (if not provided via param): %s", defaultUsername)
-	log.Printf("Default API Port (if not provided via param): %s", defaultAPIPort)
+	log.Printf("TLS Enabled by default: %t", *tlsEnabledFlag)
+	log.Printf("Default API Port (if not provided via param): %s", mikrotik.DefaultPort(*tlsEnabledFlag))
+
+	exporterRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	exporterRegistry.MustRegister(mikrotik.Collectors()...)
+
+	mikrotik.SetCapabilitiesTTL(*capabilitiesTTLFlag)
+	mikrotik.SetAddressListNamesTTL(*addressListNamesTTLFlag)
+
+	if *poolEnabledFlag {
+		connectionPool = mikrotik.NewPool(*poolIdleTimeoutFlag, *poolMaxIdleFlag)
+		defer connectionPool.Close()
+		exporterRegistry.MustRegister(connectionPool)
+		log.Printf("Connection pool enabled (idle timeout: %s, max idle per target: %d)", *poolIdleTimeoutFlag, *poolMaxIdleFlag)
+	}
+
+	if _, err := regexp.Compile(*firewallCommentIncludeFlag); err != nil {
+		log.Fatalf("Invalid -collector.firewall.comment-include: %v", err)
+	}
+	if _, err := regexp.Compile(*addressListIncludeFlag); err != nil {
+		log.Fatalf("Invalid -collector.addresslist.include: %v", err)
+	}
+
+	if *recordDirFlag != "" {
+		var err error
+		recordRedaction, err = mikrotik.ParseRedaction(*recordRedactFlag)
+		if err != nil {
+			log.Fatalf("Invalid -record.redact: %v", err)
+		}
+		log.Printf("Recording API replies to %s (redacting: %s)", *recordDirFlag, *recordRedactFlag)
+	}
+
+	exporterConfig = config.NewSafeConfig(*configFileFlag, validateConfig, exporterRegistry)
+	if *configFileFlag != "" {
+		if err := exporterConfig.Reload(); err != nil {
+			log.Fatalf("Error loading config: %v", err)
+		}
+		log.Printf("Loaded config file %s with %d module(s)", *configFileFlag, len(exporterConfig.Get().Modules))
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-hup:
+				reloadConfig("SIGHUP")
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
 	mux := http.NewServeMux()
 	mux.HandleFunc(*metricsPathFlag, handleMetricsRequest)
+	mux.Handle(*exporterPathFlag, promhttp.HandlerFor(exporterRegistry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/-/reload", handleReloadRequest)
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`<html>
@@ -48,6 +157,7 @@ func main() {
 			<body>
 			<h1>MikroTik Exporter</h1>
 			<p><a href='` + *metricsPathFlag + `'>Metrics</a></p>
+			<p><a href='` + *exporterPathFlag + `'>Exporter Metrics</a></p>
 			</body>
 			</html>`))
 	})
@@ -77,25 +187,72 @@ func main() {
 	log.Println("Server gracefully stopped")
 }
 
+// reloadConfig re-reads the configuration file, keeping the active
+// configuration if the new one is invalid.
+func reloadConfig(trigger string) error {
+	if err := exporterConfig.Reload(); err != nil {
+		log.Printf("ERROR: Config reload triggered by %s failed: %v", trigger, err)
+		return err
+	}
+	log.Printf("Config reloaded by %s with %d module(s)", trigger, len(exporterConfig.Get().Modules))
+	return nil
+}
+
+func handleReloadRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Only POST requests allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := reloadConfig("/-/reload"); err != nil {
+		http.Error(w, fmt.Sprintf("failed to reload config: %s", err), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 func handleMetricsRequest(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	target := query.Get("target")
-	user := query.Get("user")
-	password := query.Get("password")
-	port := query.Get("port")
-	collectBGPParam := query.Get("collect_bgp")
-	collectPPPParam := query.Get("collect_ppp")
-	collectWirelessParam := query.Get("collect_wireless")
+	moduleName := query.Get("module")
 
 	if target == "" {
 		http.Error(w, "'target' parameter is missing", http.StatusBadRequest)
 		return
 	}
 
+	cfg := exporterConfig.Get()
+	var module config.Module
+	hasModule := false
+	if moduleName != "" {
+		module, hasModule = cfg.Module(moduleName)
+		if !hasModule {
+			http.Error(w, fmt.Sprintf("unknown module %q", moduleName), http.StatusBadRequest)
+			return
+		}
+	} else if module, hasModule = cfg.Module(defaultModuleName); hasModule {
+		moduleName = defaultModuleName
+	}
+
+	user := query.Get("user")
+	password := query.Get("password")
+	if hasModule {
+		if user != "" || password != "" {
+			log.Printf("Scrape for target %s: ignoring 'user'/'password' parameters, credentials are taken from module '%s'", target, moduleName)
+		}
+		user = module.Username
+		password = module.Password
+	}
+
 	effectiveUser := user
 	if effectiveUser == "" {
 		effectiveUser = defaultUsername
-		log.Printf("Scrape for target %s: 'user' parameter missing, using default '%s'", target, defaultUsername)
+		log.Printf("Scrape for target %s: no user configured, using default '%s'", target, defaultUsername)
+	}
+
+	port := module.Port
+	if p := query.Get("port"); p != "" {
+		port = p
 	}
 
 	address := target
@@ -106,21 +263,233 @@ func handleMetricsRequest(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Scrape for target %s: No port specified, client will use default.", target)
 	}
 
-	collectBGP, _ := strconv.ParseBool(collectBGPParam)
-	collectPPP, _ := strconv.ParseBool(collectPPPParam)
-	collectWireless, _ := strconv.ParseBool(collectWirelessParam)
+	timeout := *scrapeTimeout
+	if module.Timeout > 0 {
+		timeout = module.Timeout
+	}
+
+	concurrency := *maxConcurrency
+	if module.MaxConcurrency > 0 {
+		concurrency = module.MaxConcurrency
+	}
+
+	enabledCollectors := make(map[string]bool, len(collectorFlags))
+	var enabledNames []string
+	for name, enabled := range collectorFlags {
+		on := *enabled
+		if v, ok := module.Collectors[name]; ok {
+			on = v
+		}
+		on = optionalBool(query, "collect_"+name, on)
+		enabledCollectors[name] = on
+		if on {
+			enabledNames = append(enabledNames, name)
+		}
+	}
+	slices.Sort(enabledNames)
 
-	log.Printf("Processing scrape request for address: %s, user: %s, collect_bgp: %t, collect_ppp: %t, collect_wireless: %t",
-		address, effectiveUser, collectBGP, collectPPP, collectWireless)
+	collectorOptions := metrics.Options{BGPAdvertisements: *bgpAdvertisementsFlag}
+	if module.BGP.Advertisements != nil {
+		collectorOptions.BGPAdvertisements = *module.BGP.Advertisements
+	}
+	collectorOptions.BGPAdvertisements = optionalBool(query, "bgp_advertisements", collectorOptions.BGPAdvertisements)
+
+	collectorOptions.DHCPLeases = *dhcpLeasesFlag
+	if module.DHCP.Leases != nil {
+		collectorOptions.DHCPLeases = *module.DHCP.Leases
+	}
+	collectorOptions.DHCPLeases = optionalBool(query, "dhcp_leases", collectorOptions.DHCPLeases)
 
-	client := mikrotik.NewClient(address, effectiveUser, password, *scrapeTimeout)
+	commentInclude := *firewallCommentIncludeFlag
+	if module.Firewall.CommentInclude != nil {
+		commentInclude = *module.Firewall.CommentInclude
+	}
+	if query.Has("firewall_comment_include") {
+		commentInclude = query.Get("firewall_comment_include")
+	}
+	if commentInclude != "" {
+		re, err := regexp.Compile(commentInclude)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid firewall comment filter: %v", err), http.StatusBadRequest)
+			return
+		}
+		collectorOptions.FirewallCommentInclude = re
+	}
+
+	listInclude := *addressListIncludeFlag
+	if module.AddressList.Include != nil {
+		listInclude = *module.AddressList.Include
+	}
+	if query.Has("addresslist_include") {
+		listInclude = query.Get("addresslist_include")
+	}
+	if listInclude != "" {
+		re, err := regexp.Compile(listInclude)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid address list filter: %v", err), http.StatusBadRequest)
+			return
+		}
+		collectorOptions.AddressListInclude = re
+	}
+
+	tlsConfig := mikrotik.TLSConfig{
+		Enabled:            *tlsEnabledFlag,
+		CAFile:             *tlsCAFileFlag,
+		CertFile:           *tlsCertFileFlag,
+		KeyFile:            *tlsKeyFileFlag,
+		ServerName:         *tlsServerNameFlag,
+		InsecureSkipVerify: *tlsInsecureSkipVerifyFlag,
+	}
+	if module.TLS != nil {
+		tlsConfig = mikrotik.TLSConfig{
+			Enabled:            module.TLS.Enabled,
+			CAFile:             module.TLS.CAFile,
+			CertFile:           module.TLS.CertFile,
+			KeyFile:            module.TLS.KeyFile,
+			ServerName:         module.TLS.ServerName,
+			InsecureSkipVerify: module.TLS.InsecureSkipVerify,
+		}
+	}
+	if tlsParam := query.Get("tls"); tlsParam != "" {
+		enabled, err := strconv.ParseBool(tlsParam)
+		if err != nil {
+			http.Error(w, "invalid 'tls' parameter", http.StatusBadRequest)
+			return
+		}
+		tlsConfig.Enabled = enabled
+	}
+	// Whoever can reach the exporter must not be able to turn off certificate
+	// verification, so it can only be skipped with the flag or in a module.
+	if query.Has("tls_insecure_skip_verify") {
+		http.Error(w, "'tls_insecure_skip_verify' parameter is not supported, use -tls.insecure-skip-verify or a module", http.StatusBadRequest)
+		return
+	}
+	if tlsServerNameParam := query.Get("tls_server_name"); tlsServerNameParam != "" {
+		tlsConfig.ServerName = tlsServerNameParam
+	}
+
+	backend := module.Backend
+	if b := query.Get("backend"); b != "" {
+		backend = b
+	}
+	if !mikrotik.ValidBackend(backend) {
+		http.Error(w, fmt.Sprintf("unknown backend %q", backend), http.StatusBadRequest)
+		return
+	}
+	if backend == "" {
+		backend = mikrotik.BackendAPI
+	}
+	var fixture *mikrotik.Fixture
+	if backend == mikrotik.BackendReplay {
+		if module.FixtureFile == "" {
+			http.Error(w, "the replay backend requires a module with a fixture_file", http.StatusBadRequest)
+			return
+		}
+		var err error
+		if fixture, err = mikrotik.LoadFixture(module.FixtureFile); err != nil {
+			log.Printf("ERROR: Scrape for target %s: %v", target, err)
+			http.Error(w, "failed to load fixture", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	ctx, cancel, err := scrapeContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer cancel()
+
+	log.Printf("Processing scrape request for address: %s, module: %s, user: %s, backend: %s, tls: %t, collectors: %v",
+		address, moduleName, effectiveUser, backend, tlsConfig.Enabled, enabledNames)
+
+	client := mikrotik.NewClient(address, effectiveUser, password, timeout)
+	client.TLS = tlsConfig
+	client.MaxConcurrency = concurrency
+	client.Backend = backend
+	client.Fixture = fixture
+	if connectionPool != nil {
+		client = connectionPool.Get(client)
+		defer connectionPool.Put(client)
+	} else {
+		defer client.Close()
+	}
+	if *recordDirFlag != "" {
+		client.Recorder = mikrotik.NewRecorder(recordRedaction)
+		defer func() { client.Recorder = nil }()
+	}
 	registry := prometheus.NewRegistry()
-	collector := metrics.NewMikrotikCollector(client, collectBGP, collectPPP, collectWireless)
+	collector := metrics.NewMikrotikCollector(ctx, client, enabledCollectors, collectorOptions)
 	registry.MustRegister(collector)
 
 	h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
 	h.ServeHTTP(w, r)
 
+	if client.Recorder != nil {
+		path := filepath.Join(*recordDirFlag, fixtureFileName(target))
+		if err := client.Recorder.WriteFile(path); err != nil {
+			log.Printf("ERROR: %v", err)
+		} else {
+			log.Printf("Recorded API replies of %s to %s", address, path)
+		}
+	}
+
 	log.Printf("Finished scrape request for address: %s", address)
-	client.Close()
+}
+
+// scrapeContext derives the context of a scrape from the request. If Prometheus
+// announces its scrape timeout, the context expires -scrape.timeout-offset
+// before it so that partial results can still be returned in time.
+func scrapeContext(r *http.Request) (context.Context, context.CancelFunc, error) {
+	header := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds")
+	if header == "" {
+		ctx, cancel := context.WithCancel(r.Context())
+		return ctx, cancel, nil
+	}
+
+	seconds, err := strconv.ParseFloat(header, 64)
+	if err != nil || seconds <= 0 {
+		return nil, nil, fmt.Errorf("invalid X-Prometheus-Scrape-Timeout-Seconds header %q", header)
+	}
+	timeout := time.Duration(seconds * float64(time.Second))
+	if timeout > *scrapeTimeoutOffset {
+		timeout -= *scrapeTimeoutOffset
+	} else {
+		log.Printf("Warning: scrape timeout %s is not larger than the offset %s, ignoring the offset", timeout, *scrapeTimeoutOffset)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	return ctx, cancel, nil
+}
+
+// fixtureFileName returns the name of the file a scrape of target is recorded to.
+func fixtureFileName(target string) string {
+	return strings.NewReplacer(":", "_", "/", "_", "\\", "_").Replace(target) + ".yml"
+}
+
+// validateConfig checks the parts of the configuration that depend on the exporter itself.
+func validateConfig(cfg *config.Config) error {
+	for name, module := range cfg.Modules {
+		for collector := range module.Collectors {
+			if !metrics.IsCollector(collector) {
+				return fmt.Errorf("module %q: unknown collector %q", name, collector)
+			}
+		}
+	}
+	return nil
+}
+
+// optionalBool returns the boolean value of the query parameter name, or
+// fallback if the parameter is missing or invalid.
+func optionalBool(query url.Values, name string, fallback bool) bool {
+	value := query.Get(name)
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: ignoring invalid '%s' parameter '%s'", name, value)
+		return fallback
+	}
+	return b
 }
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taihen/ros-exporter/pkg/config"
+	"github.com/taihen/ros-exporter/pkg/metrics"
+	"github.com/taihen/ros-exporter/pkg/mikrotik"
+	"github.com/taihen/ros-exporter/pkg/mikrotik/fakeapi"
+)
+
+const (
+	testUsername = "prometheus"
+	testPassword = "secret"
+)
+
+// startRouter starts a fake RouterOS API server answering from the fixture file.
+func startRouter(t *testing.T, fixturePath string) *fakeapi.Server {
+	t.Helper()
+	fixture, err := mikrotik.LoadFixture(fixturePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return startRouterWithFixture(t, fixture)
+}
+
+func startRouterWithFixture(t *testing.T, fixture *mikrotik.Fixture) *fakeapi.Server {
+	t.Helper()
+	srv, err := fakeapi.NewServer(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Username = testUsername
+	srv.Password = testPassword
+	t.Cleanup(func() { srv.Close() })
+	return srv
+}
+
+// scrape runs handleMetricsRequest against the router and returns the
+// response. params are added to the target, port and credential parameters.
+func scrape(t *testing.T, router *fakeapi.Server, params url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handleMetricsRequest(rec, scrapeRequest(t, router, params))
+	return rec
+}
+
+// scrapeRequest builds the request of a scrape of the router.
+func scrapeRequest(t *testing.T, router *fakeapi.Server, params url.Values) *http.Request {
+	t.Helper()
+	host, port, err := net.SplitHostPort(router.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := url.Values{
+		"target":   {host},
+		"port":     {port},
+		"user":     {testUsername},
+		"password": {testPassword},
+	}
+	for name, values := range params {
+		query[name] = values
+	}
+
+	return httptest.NewRequest(http.MethodGet, "/metrics?"+query.Encode(), nil)
+}
+
+// withMaxConcurrency runs the test with the -scrape.max-concurrency flag set to n.
+func withMaxConcurrency(t *testing.T, n int) {
+	t.Helper()
+	old := *maxConcurrency
+	*maxConcurrency = n
+	t.Cleanup(func() { *maxConcurrency = old })
+}
+
+func assertMetrics(t *testing.T, body string, want ...string) {
+	t.Helper()
+	lines := strings.Split(body, "\n")
+	for _, w := range want {
+		found := false
+		for _, line := range lines {
+			if line == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("missing line %q in exposition", w)
+		}
+	}
+}
+
+func assertNoMetric(t *testing.T, body, prefix string) {
+	t.Helper()
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, prefix) {
+			t.Errorf("unexpected line %q in exposition", line)
+		}
+	}
+}
+
+func TestMetricsRouterOSv6(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		concurrency int
+	}{
+		{"sequential", 1},
+		{"async", 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			withMaxConcurrency(t, tc.concurrency)
+			router := startRouter(t, "testdata/routeros-v6.yml")
+
+			rec := scrape(t, router, url.Values{
+				"collect_bgp":      {"true"},
+				"collect_ospf":     {"true"},
+				"collect_ppp":      {"true"},
+				"collect_wireless": {"true"},
+			})
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body: %s", rec.Code, rec.Body)
+			}
+			body := rec.Body.String()
+
+			assertMetrics(t, body,
+				`mikrotik_up 1`,
+				`mikrotik_last_scrape_error 0`,
+				`mikrotik_scrape_collector_success{collector="bgp"} 1`,
+				`mikrotik_scrape_collector_success{collector="health"} 1`,
+				`mikrotik_scrape_collector_success{collector="interface"} 1`,
+				`mikrotik_scrape_collector_success{collector="ppp"} 1`,
+				`mikrotik_scrape_collector_success{collector="routerboard"} 1`,
+				`mikrotik_scrape_collector_success{collector="system"} 1`,
+				`mikrotik_scrape_collector_success{collector="wireless"} 1`,
+				`mikrotik_system_cpu_load_percent 7`,
+				`mikrotik_system_memory_total_bytes 1.073741824e+09`,
+				`mikrotik_interface_receive_bytes_total{name="ether1"} 1.23456789e+08`,
+				`mikrotik_interface_transmit_drops_total{name="ether1"} 4`,
+				`mikrotik_interface_info{comment="uplink",mac_address="DC:2C:6E:00:00:01",name="ether1",type="ether"} 1`,
+				`mikrotik_health_voltage_volts 24.1`,
+				`mikrotik_health_temperature_celsius{sensor="cpu"} 38`,
+				`mikrotik_bgp_peer_prefix_count{name="upstream"} 850000`,
+				`mikrotik_bgp_peer_fsm_state{name="upstream",state="established"} 1`,
+				`mikrotik_bgp_peer_fsm_state{name="backup",state="idle"} 1`,
+				`mikrotik_bgp_peer_fsm_state{name="backup",state="established"} 0`,
+				`mikrotik_bgp_peer_flaps_total{name="upstream"} 0`,
+				`mikrotik_ospf_neighbor_state{address="10.10.0.2",instance="default",interface="sfp-sfpplus1",router_id="192.0.2.10",state="full"} 1`,
+				`mikrotik_ospf_neighbor_state{address="10.10.1.2",instance="default",interface="ether2",router_id="192.0.2.11",state="2-way"} 1`,
+				`mikrotik_ospf_instance_lsa_count{instance="default",type="router"} 3`,
+				`mikrotik_ppp_active_users_count 1`,
+				`mikrotik_wireless_interface_active_clients_count{interface="wlan1"} 1`,
+				`mikrotik_wireless_client_signal_strength_dbm{interface="wlan1",mac_address="AA:BB:CC:00:00:01"} -61`,
+			)
+			assertNoMetric(t, body, `mikrotik_interface_receive_bytes_total{name="pppoe-out1"}`)
+
+			if got := router.Logins(); got != 1 {
+				t.Errorf("router saw %d logins, want 1", got)
+			}
+		})
+	}
+}
+
+func TestMetricsRouterOSv7(t *testing.T) {
+	router := startRouter(t, "testdata/routeros-v7.yml")
+
+	rec := scrape(t, router, url.Values{
+		"collect_addresslist": {"true"},
+		"collect_bfd":         {"true"},
+		"collect_bgp":         {"true"},
+		"collect_conntrack":   {"true"},
+		"collect_dhcp":        {"true"},
+		"collect_firewall":    {"true"},
+		"collect_pool":        {"true"},
+		"collect_routes":      {"true"},
+		"collect_wireless":    {"true"},
+		"bgp_advertisements":  {"true"},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", rec.Code, rec.Body)
+	}
+	body := rec.Body.String()
+
+	// BGP is read from the RouterOS 7 session menu and the wireless package is
+	// missing: both collectors succeed.
+	assertMetrics(t, body,
+		`mikrotik_up 1`,
+		`mikrotik_last_scrape_error 0`,
+		`mikrotik_scrape_collector_success{collector="bgp"} 1`,
+		`mikrotik_scrape_collector_success{collector="wireless"} 1`,
+		`mikrotik_system_cpu_load_percent 3`,
+		`mikrotik_interface_receive_drops_total{name="sfp-sfpplus1"} 7`,
+	)
+	assertNoMetric(t, body, "mikrotik_bgp_peer_info")
+	assertNoMetric(t, body, "mikrotik_wireless_")
+	assertNoMetric(t, body, "mikrotik_dhcp_lease_")
+
+	assertMetrics(t, body,
+		`mikrotik_routeros_info{channel="stable",version="7.12.1"} 1`,
+		`mikrotik_health_temperature_celsius{sensor="cpu"} 48`,
+		`mikrotik_bgp_session_info{afi="ip,ipv6",connection="upstream",local_address="192.0.2.2",local_as="64500",local_id="198.51.100.1",local_role="ebgp",name="upstream-1",remote_address="192.0.2.1",remote_as="64496",remote_id="192.0.2.1",template="default"} 1`,
+		`mikrotik_bgp_session_established{name="upstream-1"} 1`,
+		`mikrotik_bgp_session_established{name="rr-1"} 0`,
+		`mikrotik_bgp_peer_fsm_state{name="upstream",state="established"} 1`,
+		`mikrotik_bgp_peer_fsm_state{name="rr",state="opensent"} 1`,
+		`mikrotik_bgp_session_prefix_count{name="upstream-1"} 951234`,
+		`mikrotik_bgp_session_hold_time_seconds{name="upstream-1"} 90`,
+		`mikrotik_bgp_session_received_prefixes{afi="ipv4",name="upstream-1"} 750000`,
+		`mikrotik_bgp_session_filtered_prefixes{afi="ipv4",name="upstream-1"} 12`,
+		`mikrotik_bgp_session_advertised_prefixes{afi="ipv6",name="upstream-1"} 1`,
+		`mikrotik_bfd_session_state{interface="sfp-sfpplus1",remote_address="192.0.2.1",state="up",vrf="main"} 1`,
+		`mikrotik_bfd_session_state{interface="ether1",remote_address="198.51.100.254",state="down",vrf="main"} 1`,
+		`mikrotik_bfd_session_desired_tx_interval_seconds{interface="sfp-sfpplus1",remote_address="192.0.2.1",vrf="main"} 0.1`,
+		`mikrotik_routes_count{active="true",afi="ipv4",protocol="bgp",table="main"} 749988`,
+		`mikrotik_routes_count{active="false",afi="ipv4",protocol="bgp",table="main"} 12`,
+		`mikrotik_routes_table_count{active="true",afi="ipv6",table="main"} 201236`,
+		`mikrotik_routes_table_count{active="true",afi="ipv4",table="mgmt"} 1`,
+		`mikrotik_firewall_rule_packets_total{action="fasttrack-connection",chain="forward",comment="defconf: fasttrack",family="ipv4",rule="74505280",table="filter"} 1.234567e+06`,
+		`mikrotik_conntrack_entries 250000`,
+		`mikrotik_conntrack_max_entries 4.194304e+06`,
+		`mikrotik_conntrack_protocol_entries{protocol="udp"} 65000`,
+		`mikrotik_conntrack_tcp_state_entries{state="syn-received"} 6000`,
+		`mikrotik_conntrack_timeout_seconds{timeout="tcp-established"} 86400`,
+		`mikrotik_conntrack_info{enabled="auto"} 1`,
+		`mikrotik_firewall_address_list_entries{family="ipv4",list="bogons",type="static"} 14`,
+		`mikrotik_firewall_address_list_entries{family="ipv6",list="bad_ipv6",type="dynamic"} 1`,
+		`mikrotik_dhcp_server_leases{server="cpe",status="offered"} 15`,
+		`mikrotik_dhcp_server_pool_addresses{pool="cpe-pool",server="cpe"} 2045`,
+		`mikrotik_dhcp_server_pool_used_addresses{pool="cpe-pool",server="cpe"} 1805`,
+		`mikrotik_dhcp_server_lease_time_seconds{server="cpe"} 86400`,
+		`mikrotik_ip_pool_size{family="ipv4",pool="cpe-pool"} 2045`,
+		`mikrotik_ip_pool_used{family="ipv4",pool="cpe-pool"} 1805`,
+		`mikrotik_ip_pool_info{family="ipv4",next_pool="cpe-overflow",pool="cpe-pool"} 1`,
+		`mikrotik_ip_pool_size{family="ipv6",pool="cpe-pd"} 65536`,
+		`mikrotik_ip_pool_used{family="ipv6",pool="cpe-pd"} 1790`,
+	)
+
+	// The RouterOS 6 BGP menus are skipped once the version is known.
+	commands := router.Commands()
+	for _, path := range []string{"/routing/bgp/peer/print", "/ip/bgp/peer/print", "/interface/wireless/print"} {
+		if commands[path] != 0 {
+			t.Errorf("%s was sent %d times, want 0", path, commands[path])
+		}
+	}
+	// The sessions and connections of RouterOS 7 share one print.
+	for _, path := range []string{"/system/package/print", "/routing/bgp/connection/print"} {
+		if got := commands[path]; got != 1 {
+			t.Errorf("%s was sent %d times, want 1", path, got)
+		}
+	}
+}
+
+func TestMetricsScrapeTimeout(t *testing.T) {
+	fixture, err := mikrotik.LoadFixture("testdata/routeros-v6.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range fixture.Commands {
+		if fixture.Commands[i].Command == "/system/routerboard/print" {
+			fixture.Commands[i].Delay = 2 * time.Second
+		}
+	}
+	router := startRouterWithFixture(t, fixture)
+
+	// Prometheus gives up after a second; the exporter answers the offset of
+	// 500ms earlier with what it collected so far.
+	req := scrapeRequest(t, router, nil)
+	req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", "1")
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	handleMetricsRequest(rec, req)
+	elapsed := time.Since(start)
+
+	if elapsed < 400*time.Millisecond || elapsed > 900*time.Millisecond {
+		t.Errorf("scrape took %s, want about 500ms", elapsed)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", rec.Code, rec.Body)
+	}
+	body := rec.Body.String()
+	assertMetrics(t, body,
+		`mikrotik_up 1`,
+		`mikrotik_scrape_collector_success{collector="health"} 1`,
+		`mikrotik_scrape_collector_success{collector="interface"} 1`,
+		`mikrotik_scrape_collector_success{collector="routerboard"} 0`,
+		`mikrotik_scrape_collector_success{collector="system"} 1`,
+		`mikrotik_system_cpu_load_percent 7`,
+		`mikrotik_interface_receive_bytes_total{name="ether1"} 1.23456789e+08`,
+		`mikrotik_health_voltage_volts 24.1`,
+	)
+	assertNoMetric(t, body, "mikrotik_system_info")
+}
+
+func TestMetricsCollectorTrap(t *testing.T) {
+	fixture, err := mikrotik.LoadFixture("testdata/routeros-v6.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range fixture.Commands {
+		if fixture.Commands[i].Command == "/system/resource/print" {
+			fixture.Commands[i].Trap = "failure: not enough permissions"
+		}
+	}
+	router := startRouterWithFixture(t, fixture)
+
+	body := scrape(t, router, nil).Body.String()
+
+	assertMetrics(t, body,
+		`mikrotik_up 1`,
+		`mikrotik_last_scrape_error 1`,
+		`mikrotik_scrape_collector_success{collector="system"} 0`,
+		`mikrotik_scrape_collector_success{collector="interface"} 1`,
+	)
+	assertNoMetric(t, body, "mikrotik_system_cpu_load_percent")
+}
+
+func TestMetricsLoginFailure(t *testing.T) {
+	router := startRouter(t, "testdata/routeros-v6.yml")
+
+	body := scrape(t, router, url.Values{"password": {"wrong"}}).Body.String()
+
+	assertMetrics(t, body,
+		`mikrotik_up 0`,
+		`mikrotik_last_scrape_error 1`,
+		`mikrotik_scrape_collector_success{collector="system"} 0`,
+	)
+	if got := router.Logins(); got != 0 {
+		t.Errorf("router saw %d logins, want 0", got)
+	}
+}
+
+func TestMetricsMissingTarget(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleMetricsRequest(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestMetricsInsecureSkipVerifyParam(t *testing.T) {
+	router := startRouter(t, "testdata/routeros-v6.yml")
+
+	rec := scrape(t, router, url.Values{"tls": {"true"}, "tls_insecure_skip_verify": {"true"}})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if got := router.Logins(); got != 0 {
+		t.Errorf("router saw %d logins, want 0", got)
+	}
+}
+
+// withConfig activates a configuration file with the given content and
+// returns its path.
+func withConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ros-exporter.yml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := exporterConfig
+	exporterConfig = config.NewSafeConfig(path, validateConfig, nil)
+	t.Cleanup(func() { exporterConfig = old })
+	if err := exporterConfig.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// withoutDurations drops the scrape duration series, which differ between scrapes.
+func withoutDurations(body string) string {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if !strings.Contains(line, "duration_seconds") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestMetricsRecordAndReplay(t *testing.T) {
+	router := startRouter(t, "testdata/routeros-v6.yml")
+	// The BGP state change timestamps are derived from the scrape time; both
+	// scrapes must see the same clock.
+	t.Cleanup(metrics.SetClock(func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }))
+	collectAll := url.Values{
+		"collect_bgp":      {"true"},
+		"collect_ppp":      {"true"},
+		"collect_wireless": {"true"},
+	}
+
+	dir := t.TempDir()
+	*recordDirFlag = dir
+	recordRedaction = mikrotik.Redaction{}
+	t.Cleanup(func() { *recordDirFlag = "" })
+
+	live := scrape(t, router, collectAll).Body.String()
+	*recordDirFlag = ""
+
+	host, _, _ := net.SplitHostPort(router.Addr())
+	fixturePath := filepath.Join(dir, fixtureFileName(host))
+	withConfig(t, "modules:\n  replay:\n    backend: replay\n    fixture_file: "+fixturePath+"\n")
+
+	query := url.Values{"target": {"recorded-router"}, "module": {"replay"}}
+	for name, values := range collectAll {
+		query[name] = values
+	}
+	rec := httptest.NewRecorder()
+	handleMetricsRequest(rec, httptest.NewRequest(http.MethodGet, "/metrics?"+query.Encode(), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", rec.Code, rec.Body)
+	}
+
+	if got, want := withoutDurations(rec.Body.String()), withoutDurations(live); got != want {
+		t.Errorf("replayed scrape differs from live scrape:\n--- live\n%s\n--- replay\n%s", want, got)
+	}
+}
+
+func TestReloadEndpoint(t *testing.T) {
+	path := withConfig(t, "modules:\n  default:\n    username: prometheus\n")
+
+	reload := func(method string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handleReloadRequest(rec, httptest.NewRequest(method, "/-/reload", nil))
+		return rec
+	}
+
+	if err := os.WriteFile(path, []byte("modules:\n  default:\n    username: monitoring\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if rec := reload(http.MethodPost); rec.Code != http.StatusOK {
+		t.Errorf("POST status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if module, _ := exporterConfig.Get().Module("default"); module.Username != "monitoring" {
+		t.Errorf("username = %q after reload, want monitoring", module.Username)
+	}
+
+	// A broken file is rejected and the previous configuration stays active.
+	if err := os.WriteFile(path, []byte("modules:\n  default:\n    backend: ssh\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rec := reload(http.MethodPost)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("POST status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if !strings.Contains(rec.Body.String(), `unknown backend "ssh"`) {
+		t.Errorf("POST body = %q, want the reload error", rec.Body.String())
+	}
+	if module, _ := exporterConfig.Get().Module("default"); module.Username != "monitoring" {
+		t.Errorf("username = %q after a failed reload, want monitoring", module.Username)
+	}
+
+	rec = reload(http.MethodGet)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if got := rec.Header().Get("Allow"); got != http.MethodPost {
+		t.Errorf("GET Allow header = %q, want %q", got, http.MethodPost)
+	}
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// Config is the exporter configuration file.
+type Config struct {
+	Modules map[string]Module `yaml:"modules"`
+}
+
+// Module describes how to connect to and what to collect from a group of targets.
+type Module struct {
+	Username     string `yaml:"username"`
+	UsernameEnv  string `yaml:"username_env"`
+	UsernameFile string `yaml:"username_file"`
+	Password     string `yaml:"password"`
+	PasswordEnv  string `yaml:"password_env"`
+	PasswordFile string `yaml:"password_file"`
+
+	Backend        string             `yaml:"backend"`
+	FixtureFile    string             `yaml:"fixture_file"`
+	Port           string             `yaml:"port"`
+	Timeout        time.Duration      `yaml:"timeout"`
+	MaxConcurrency int                `yaml:"max_concurrency"`
+	TLS            *TLSConfig         `yaml:"tls"`
+	Collectors     map[string]bool    `yaml:"collectors"`
+	BGP            BGPOptions         `yaml:"bgp"`
+	Firewall       FirewallOptions    `yaml:"firewall"`
+	AddressList    AddressListOptions `yaml:"addresslist"`
+	DHCP           DHCPOptions        `yaml:"dhcp"`
+}
+
+// BGPOptions tune the bgp collector of a module. Unset options fall back to
+// the command-line flags.
+type BGPOptions struct {
+	Advertisements *bool `yaml:"advertisements"`
+}
+
+// FirewallOptions tune the firewall collector of a module.
+type FirewallOptions struct {
+	// CommentInclude is a regular expression selecting the rules to export by
+	// comment. Unset falls back to the command-line flag.
+	CommentInclude *string `yaml:"comment_include"`
+}
+
+// AddressListOptions tune the addresslist collector of a module.
+type AddressListOptions struct {
+	// Include is a regular expression selecting the address lists to count
+	// by name. Unset falls back to the command-line flag.
+	Include *string `yaml:"include"`
+}
+
+// DHCPOptions tune the dhcp collector of a module. Unset options fall back to
+// the command-line flags.
+type DHCPOptions struct {
+	Leases *bool `yaml:"leases"`
+}
+
+// TLSConfig holds the API-SSL options of a module.
+type TLSConfig struct {
+	Enabled            bool   `yaml:"enabled"`
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// Load reads the configuration file at path, resolves credentials from the
+// environment and secret files, and validates every module.
+func Load(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(content, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	for name, module := range cfg.Modules {
+		if err := module.resolve(); err != nil {
+			return nil, fmt.Errorf("module %q: %w", name, err)
+		}
+		cfg.Modules[name] = module
+	}
+
+	return cfg, nil
+}
+
+// Module returns the module with the given name.
+func (c *Config) Module(name string) (Module, bool) {
+	if c == nil {
+		return Module{}, false
+	}
+	module, ok := c.Modules[name]
+	return module, ok
+}
+
+func (m *Module) resolve() error {
+	username, err := resolveSecret("username", m.Username, m.UsernameEnv, m.UsernameFile)
+	if err != nil {
+		return err
+	}
+	password, err := resolveSecret("password", m.Password, m.PasswordEnv, m.PasswordFile)
+	if err != nil {
+		return err
+	}
+	m.Username = username
+	m.Password = password
+
+	switch m.Backend {
+	case "", "api", "rest":
+	case "replay":
+		if m.FixtureFile == "" {
+			return errors.New("backend replay requires fixture_file")
+		}
+	default:
+		return fmt.Errorf("unknown backend %q, must be api, rest or replay", m.Backend)
+	}
+	if m.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	if m.MaxConcurrency < 0 {
+		return errors.New("max_concurrency must not be negative")
+	}
+	if m.TLS != nil && (m.TLS.CertFile == "") != (m.TLS.KeyFile == "") {
+		return errors.New("tls: both cert_file and key_file must be set")
+	}
+	if m.Firewall.CommentInclude != nil {
+		if _, err := regexp.Compile(*m.Firewall.CommentInclude); err != nil {
+			return fmt.Errorf("firewall: invalid comment_include: %w", err)
+		}
+	}
+	if m.AddressList.Include != nil {
+		if _, err := regexp.Compile(*m.AddressList.Include); err != nil {
+			return fmt.Errorf("addresslist: invalid include: %w", err)
+		}
+	}
+	return nil
+}
+
+// resolveSecret returns the value of a credential that may be given inline,
+// through an environment variable or in a separate file.
+func resolveSecret(field, value, envName, file string) (string, error) {
+	sources := 0
+	for _, s := range []string{value, envName, file} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return "", fmt.Errorf("only one of %s, %s_env and %s_file may be set", field, field, field)
+	}
+
+	switch {
+	case envName != "":
+		v, ok := os.LookupEnv(envName)
+		if !ok {
+			return "", fmt.Errorf("%s_env: environment variable %s is not set", field, envName)
+		}
+		return v, nil
+	case file != "":
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("%s_file: %w", field, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	default:
+		return value, nil
+	}
+}
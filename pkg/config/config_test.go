@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			content: `modules:
+  default:
+    username: prometheus
+    password: secret
+    timeout: 5s
+    tls:
+      enabled: true
+    collectors:
+      bgp: true
+`,
+		},
+		{
+			name:    "unknown top-level key",
+			content: "module:\n  default:\n    username: prometheus\n",
+			wantErr: "field module not found",
+		},
+		{
+			name:    "misspelled module key",
+			content: "modules:\n  default:\n    username: prometheus\n    pasword: secret\n",
+			wantErr: "field pasword not found",
+		},
+		{
+			name:    "unknown nested key",
+			content: "modules:\n  default:\n    tls:\n      insecure: true\n",
+			wantErr: "field insecure not found",
+		},
+		{
+			name:    "invalid module",
+			content: "modules:\n  default:\n    backend: ssh\n",
+			wantErr: `module "default": unknown backend "ssh"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := Load(writeFile(t, "config.yml", tc.content))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			module, ok := cfg.Module("default")
+			if !ok || module.Username != "prometheus" || module.Password != "secret" || !module.Collectors["bgp"] {
+				t.Errorf("Load() module = %+v, %v", module, ok)
+			}
+		})
+	}
+}
+
+func TestResolveSecret(t *testing.T) {
+	t.Setenv("ROS_EXPORTER_TEST_PASSWORD", "from-env")
+	file := writeFile(t, "password", "from-file\n")
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	for _, tc := range []struct {
+		name                 string
+		value, envName, file string
+		want                 string
+		wantErr              string
+	}{
+		{name: "inline", value: "inline", want: "inline"},
+		{name: "env", envName: "ROS_EXPORTER_TEST_PASSWORD", want: "from-env"},
+		{name: "file", file: file, want: "from-file"},
+		{name: "unset", want: ""},
+		{name: "inline and env", value: "inline", envName: "ROS_EXPORTER_TEST_PASSWORD", wantErr: "only one of password, password_env and password_file"},
+		{name: "env and file", envName: "ROS_EXPORTER_TEST_PASSWORD", file: file, wantErr: "only one of password, password_env and password_file"},
+		{name: "missing env", envName: "ROS_EXPORTER_TEST_UNSET", wantErr: "password_env: environment variable ROS_EXPORTER_TEST_UNSET is not set"},
+		{name: "missing file", file: missing, wantErr: "password_file:"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolveSecret("password", tc.value, tc.envName, tc.file)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("resolveSecret() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("resolveSecret() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
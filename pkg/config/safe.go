@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrNoConfigFile is returned when a reload is requested without a configuration file.
+var ErrNoConfigFile = errors.New("no configuration file specified")
+
+// SafeConfig holds the active configuration and allows it to be replaced
+// atomically while scrapes are in flight.
+type SafeConfig struct {
+	mu       sync.RWMutex
+	cfg      *Config
+	path     string
+	validate func(*Config) error
+
+	reloadSuccess     prometheus.Gauge
+	reloadSuccessTime prometheus.Gauge
+}
+
+// NewSafeConfig returns a SafeConfig for the file at path. An empty path
+// yields an empty configuration that cannot be reloaded, and the reload
+// metrics are not registered then. validate, if not nil, is run on every
+// loaded configuration before it becomes active.
+func NewSafeConfig(path string, validate func(*Config) error, reg prometheus.Registerer) *SafeConfig {
+	sc := &SafeConfig{
+		cfg:      &Config{},
+		path:     path,
+		validate: validate,
+		reloadSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "ros_exporter",
+			Name:      "config_last_reload_successful",
+			Help:      "Whether the last configuration reload attempt was successful.",
+		}),
+		reloadSuccessTime: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "ros_exporter",
+			Name:      "config_last_reload_success_timestamp_seconds",
+			Help:      "Timestamp of the last successful configuration reload.",
+		}),
+	}
+	if reg != nil && path != "" {
+		reg.MustRegister(sc.reloadSuccess, sc.reloadSuccessTime)
+	}
+	return sc
+}
+
+// Get returns the active configuration. It must not be modified.
+func (sc *SafeConfig) Get() *Config {
+	if sc == nil {
+		return nil
+	}
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.cfg
+}
+
+// Reload reads and validates the configuration file and makes it active.
+// On error the previous configuration is kept.
+func (sc *SafeConfig) Reload() (err error) {
+	if sc.path == "" {
+		return ErrNoConfigFile
+	}
+
+	defer func() {
+		if err != nil {
+			sc.reloadSuccess.Set(0)
+			return
+		}
+		sc.reloadSuccess.Set(1)
+		sc.reloadSuccessTime.Set(float64(time.Now().Unix()))
+	}()
+
+	cfg, err := Load(sc.path)
+	if err != nil {
+		return err
+	}
+	if sc.validate != nil {
+		if err := sc.validate(cfg); err != nil {
+			return err
+		}
+	}
+
+	sc.mu.Lock()
+	sc.cfg = cfg
+	sc.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSafeConfigReload(t *testing.T) {
+	path := writeFile(t, "config.yml", "modules:\n  default:\n    username: prometheus\n")
+	reg := prometheus.NewRegistry()
+	sc := NewSafeConfig(path, nil, reg)
+
+	if err := sc.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sc.Get().Module("default"); !ok {
+		t.Fatal("module default not loaded")
+	}
+	if got := testutil.ToFloat64(sc.reloadSuccess); got != 1 {
+		t.Errorf("config_last_reload_successful = %v, want 1", got)
+	}
+	successTime := testutil.ToFloat64(sc.reloadSuccessTime)
+	if successTime == 0 {
+		t.Error("config_last_reload_success_timestamp_seconds not set")
+	}
+
+	// A broken file keeps the previous configuration.
+	if err := os.WriteFile(path, []byte("modules:\n  default:\n    usernme: prometheus\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.Reload(); err == nil {
+		t.Fatal("Reload() of a broken file succeeded")
+	}
+	if module, ok := sc.Get().Module("default"); !ok || module.Username != "prometheus" {
+		t.Errorf("Reload() of a broken file replaced the configuration: %+v", sc.Get())
+	}
+	if got := testutil.ToFloat64(sc.reloadSuccess); got != 0 {
+		t.Errorf("config_last_reload_successful = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(sc.reloadSuccessTime); got != successTime {
+		t.Errorf("config_last_reload_success_timestamp_seconds = %v, want %v", got, successTime)
+	}
+
+	// So does a configuration rejected by validate.
+	if err := os.WriteFile(path, []byte("modules:\n  other: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sc.validate = func(*Config) error { return errors.New("rejected") }
+	if err := sc.Reload(); err == nil {
+		t.Fatal("Reload() of a rejected configuration succeeded")
+	}
+	if _, ok := sc.Get().Module("other"); ok {
+		t.Error("Reload() activated a rejected configuration")
+	}
+
+	if got, err := testutil.GatherAndCount(reg); err != nil || got != 2 {
+		t.Errorf("registry holds %d metrics (%v), want 2", got, err)
+	}
+}
+
+func TestSafeConfigWithoutFile(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	sc := NewSafeConfig("", nil, reg)
+
+	if err := sc.Reload(); !errors.Is(err, ErrNoConfigFile) {
+		t.Errorf("Reload() error = %v, want %v", err, ErrNoConfigFile)
+	}
+	if got, err := testutil.GatherAndCount(reg); err != nil || got != 0 {
+		t.Errorf("registry holds %d reload metrics (%v), want none without a file", got, err)
+	}
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("addresslist", false, newAddressListCollector)
+}
+
+type addressListCollector struct {
+	opts Options
+
+	entriesDesc *prometheus.Desc
+}
+
+func newAddressListCollector(opts Options) SubCollector {
+	return &addressListCollector{
+		opts: opts,
+		entriesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "firewall_address_list", "entries"),
+			"Number of entries of the firewall address list, by whether they are static or dynamic.",
+			[]string{"family", "list", "type"},
+			nil,
+		),
+	}
+}
+
+func (c *addressListCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.entriesDesc
+}
+
+func (c *addressListCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	counts, err := client.GetAddressListCounts(ctx, c.opts.AddressListInclude)
+	if err != nil {
+		return err
+	}
+
+	for _, lc := range counts {
+		ch <- prometheus.MustNewConstMetric(c.entriesDesc, prometheus.GaugeValue, float64(lc.Static), lc.Family, lc.List, "static")
+		ch <- prometheus.MustNewConstMetric(c.entriesDesc, prometheus.GaugeValue, float64(lc.Dynamic), lc.Family, lc.List, "dynamic")
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("bfd", false, newBFDCollector)
+}
+
+// bfdStates are the states of a BFD session (RFC 5880), reported as one
+// series each by the state-set metric.
+var bfdStates = []string{"admin-down", "down", "init", "up"}
+
+type bfdCollector struct {
+	infoDesc              *prometheus.Desc
+	stateDesc             *prometheus.Desc
+	stateChangesDesc      *prometheus.Desc
+	uptimeDesc            *prometheus.Desc
+	desiredTxIntervalDesc *prometheus.Desc
+	requiredMinRxDesc     *prometheus.Desc
+	remoteMinRxDesc       *prometheus.Desc
+	actualTxIntervalDesc  *prometheus.Desc
+	multiplierDesc        *prometheus.Desc
+	packetsRxDesc         *prometheus.Desc
+	packetsTxDesc         *prometheus.Desc
+}
+
+func newBFDCollector(Options) SubCollector {
+	labels := []string{"remote_address", "interface", "vrf"}
+	return &bfdCollector{
+		infoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bfd_session", "info"),
+			"BFD session information.",
+			[]string{"remote_address", "interface", "vrf", "local_address"},
+			nil,
+		),
+		stateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bfd_session", "state"),
+			"BFD session state, 1 for the current state and 0 for the others.",
+			[]string{"remote_address", "interface", "vrf", "state"},
+			nil,
+		),
+		stateChangesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bfd_session", "state_changes_total"),
+			"Number of state changes of the BFD session reported by the router.",
+			labels,
+			nil,
+		),
+		uptimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bfd_session", "uptime_seconds"),
+			"BFD session uptime in seconds.",
+			labels,
+			nil,
+		),
+		desiredTxIntervalDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bfd_session", "desired_tx_interval_seconds"),
+			"Configured minimum interval between transmitted BFD packets in seconds.",
+			labels,
+			nil,
+		),
+		requiredMinRxDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bfd_session", "required_min_rx_interval_seconds"),
+			"Configured minimum interval between received BFD packets in seconds.",
+			labels,
+			nil,
+		),
+		remoteMinRxDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bfd_session", "remote_min_rx_interval_seconds"),
+			"Minimum interval between received BFD packets required by the remote system in seconds.",
+			labels,
+			nil,
+		),
+		actualTxIntervalDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bfd_session", "actual_tx_interval_seconds"),
+			"Negotiated interval between transmitted BFD packets in seconds.",
+			labels,
+			nil,
+		),
+		multiplierDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bfd_session", "multiplier"),
+			"Number of missed BFD packets after which the session goes down.",
+			labels,
+			nil,
+		),
+		packetsRxDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bfd_session", "received_packets_total"),
+			"Total number of BFD packets received.",
+			labels,
+			nil,
+		),
+		packetsTxDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bfd_session", "transmitted_packets_total"),
+			"Total number of BFD packets transmitted.",
+			labels,
+			nil,
+		),
+	}
+}
+
+func (c *bfdCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.infoDesc
+	ch <- c.stateDesc
+	ch <- c.stateChangesDesc
+	ch <- c.uptimeDesc
+	ch <- c.desiredTxIntervalDesc
+	ch <- c.requiredMinRxDesc
+	ch <- c.remoteMinRxDesc
+	ch <- c.actualTxIntervalDesc
+	ch <- c.multiplierDesc
+	ch <- c.packetsRxDesc
+	ch <- c.packetsTxDesc
+}
+
+func (c *bfdCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	sessions, err := client.GetBFDSessions(ctx)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[[3]string]bool, len(sessions))
+	for _, s := range sessions {
+		// Sessions are identified by remote address, interface and VRF; a
+		// second session with the same labels would fail the whole scrape.
+		key := [3]string{s.RemoteAddress, s.Interface, s.VRF}
+		if seen[key] {
+			log.Printf("Warning: Skipping duplicate BFD session to %s on interface '%s' in VRF '%s' of %s", s.RemoteAddress, s.Interface, s.VRF, client.Target())
+			continue
+		}
+		seen[key] = true
+
+		ch <- prometheus.MustNewConstMetric(c.infoDesc, prometheus.GaugeValue, 1, s.RemoteAddress, s.Interface, s.VRF, s.LocalAddress)
+
+		state := strings.ToLower(s.State)
+		for _, known := range bfdStates {
+			value := 0.0
+			if known == state {
+				value = 1.0
+			}
+			ch <- prometheus.MustNewConstMetric(c.stateDesc, prometheus.GaugeValue, value, s.RemoteAddress, s.Interface, s.VRF, known)
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.stateChangesDesc, prometheus.CounterValue, float64(s.StateChanges), s.RemoteAddress, s.Interface, s.VRF)
+		ch <- prometheus.MustNewConstMetric(c.uptimeDesc, prometheus.GaugeValue, s.Uptime.Seconds(), s.RemoteAddress, s.Interface, s.VRF)
+		ch <- prometheus.MustNewConstMetric(c.desiredTxIntervalDesc, prometheus.GaugeValue, s.DesiredTxInterval.Seconds(), s.RemoteAddress, s.Interface, s.VRF)
+		ch <- prometheus.MustNewConstMetric(c.requiredMinRxDesc, prometheus.GaugeValue, s.RequiredMinRx.Seconds(), s.RemoteAddress, s.Interface, s.VRF)
+		ch <- prometheus.MustNewConstMetric(c.remoteMinRxDesc, prometheus.GaugeValue, s.RemoteMinRx.Seconds(), s.RemoteAddress, s.Interface, s.VRF)
+		ch <- prometheus.MustNewConstMetric(c.actualTxIntervalDesc, prometheus.GaugeValue, s.ActualTxInterval.Seconds(), s.RemoteAddress, s.Interface, s.VRF)
+		ch <- prometheus.MustNewConstMetric(c.multiplierDesc, prometheus.GaugeValue, float64(s.Multiplier), s.RemoteAddress, s.Interface, s.VRF)
+		ch <- prometheus.MustNewConstMetric(c.packetsRxDesc, prometheus.CounterValue, float64(s.PacketsRx), s.RemoteAddress, s.Interface, s.VRF)
+		ch <- prometheus.MustNewConstMetric(c.packetsTxDesc, prometheus.CounterValue, float64(s.PacketsTx), s.RemoteAddress, s.Interface, s.VRF)
+	}
+	return nil
+}
@@ -0,0 +1,384 @@
+package metrics
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/taihen/ros-exporter/pkg/mikrotik"
+)
+
+func init() {
+	registerCollector("bgp", false, newBGPCollector)
+}
+
+type bgpCollector struct {
+	advertisements bool
+
+	peerInfoDesc          *prometheus.Desc
+	peerStateDesc         *prometheus.Desc
+	peerFSMStateDesc      *prometheus.Desc
+	peerFlapsDesc         *prometheus.Desc
+	peerLastChangeDesc    *prometheus.Desc
+	peerUptimeDesc        *prometheus.Desc
+	peerPrefixCountDesc   *prometheus.Desc
+	peerUpdatesSentDesc   *prometheus.Desc
+	peerUpdatesRecvDesc   *prometheus.Desc
+	peerWithdrawsSentDesc *prometheus.Desc
+	peerWithdrawsRecvDesc *prometheus.Desc
+	peerAdvertisedDesc    *prometheus.Desc
+
+	sessionInfoDesc             *prometheus.Desc
+	sessionEstablishedDesc      *prometheus.Desc
+	sessionFlapsDesc            *prometheus.Desc
+	sessionLastChangeDesc       *prometheus.Desc
+	sessionUptimeDesc           *prometheus.Desc
+	sessionHoldTimeDesc         *prometheus.Desc
+	sessionKeepaliveTimeDesc    *prometheus.Desc
+	sessionPrefixCountDesc      *prometheus.Desc
+	sessionMessagesSentDesc     *prometheus.Desc
+	sessionMessagesReceivedDesc *prometheus.Desc
+	sessionBytesSentDesc        *prometheus.Desc
+	sessionBytesReceivedDesc    *prometheus.Desc
+	sessionReceivedDesc         *prometheus.Desc
+	sessionAcceptedDesc         *prometheus.Desc
+	sessionFilteredDesc         *prometheus.Desc
+	sessionAdvertisedDesc       *prometheus.Desc
+}
+
+func newBGPCollector(opts Options) SubCollector {
+	return &bgpCollector{
+		advertisements: opts.BGPAdvertisements,
+
+		peerInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_peer", "info"),
+			"BGP peer information.",
+			[]string{"name", "instance", "remote_address", "remote_as", "local_address", "local_role", "remote_role", "disabled"},
+			nil,
+		),
+		peerStateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_peer", "state"),
+			"BGP peer state (1 = Established, 0 = Other).",
+			[]string{"name", "state_text"},
+			nil,
+		),
+		peerFSMStateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_peer", "fsm_state"),
+			"BGP peer finite state machine state, 1 for the current state and 0 for the others. On RouterOS 7 the name is the connection and the state is derived from its sessions.",
+			[]string{"name", "state"},
+			nil,
+		),
+		peerFlapsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_peer", "flaps_total"),
+			"Number of times the exporter saw the BGP peer leave the established state or its uptime reset.",
+			[]string{"name"},
+			nil,
+		),
+		peerLastChangeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_peer", "last_state_change_timestamp_seconds"),
+			"Time of the last observed state change of the BGP peer, derived from its uptime while established.",
+			[]string{"name"},
+			nil,
+		),
+		peerUptimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_peer", "uptime_seconds"),
+			"BGP peer session uptime in seconds.",
+			[]string{"name"},
+			nil,
+		),
+		peerPrefixCountDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_peer", "prefix_count"),
+			"Number of prefixes received from the BGP peer in all address families (RouterOS 6 does not count them per address family).",
+			[]string{"name"},
+			nil,
+		),
+		peerUpdatesSentDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_peer", "updates_sent_total"),
+			"Total number of BGP update messages sent.",
+			[]string{"name"},
+			nil,
+		),
+		peerUpdatesRecvDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_peer", "updates_received_total"),
+			"Total number of BGP update messages received.",
+			[]string{"name"},
+			nil,
+		),
+		peerWithdrawsSentDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_peer", "withdraws_sent_total"),
+			"Total number of BGP withdraw messages sent.",
+			[]string{"name"},
+			nil,
+		),
+		peerWithdrawsRecvDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_peer", "withdraws_received_total"),
+			"Total number of BGP withdraw messages received.",
+			[]string{"name"},
+			nil,
+		),
+		peerAdvertisedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_peer", "advertised_prefixes"),
+			"Number of prefixes advertised to the BGP peer by address family.",
+			[]string{"name", "afi"},
+			nil,
+		),
+
+		sessionInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "info"),
+			"BGP session information (RouterOS 7).",
+			[]string{"name", "connection", "template", "remote_address", "remote_as", "remote_id", "local_address", "local_as", "local_id", "local_role", "afi"},
+			nil,
+		),
+		sessionEstablishedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "established"),
+			"Whether the BGP session is established (1 = Established, 0 = Other).",
+			[]string{"name"},
+			nil,
+		),
+		sessionFlapsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "flaps_total"),
+			"Number of times the exporter saw the BGP session leave the established state or its uptime reset.",
+			[]string{"name"},
+			nil,
+		),
+		sessionLastChangeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "last_state_change_timestamp_seconds"),
+			"Time of the last observed state change of the BGP session, derived from its uptime while established.",
+			[]string{"name"},
+			nil,
+		),
+		sessionUptimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "uptime_seconds"),
+			"BGP session uptime in seconds.",
+			[]string{"name"},
+			nil,
+		),
+		sessionHoldTimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "hold_time_seconds"),
+			"Negotiated BGP hold time in seconds.",
+			[]string{"name"},
+			nil,
+		),
+		sessionKeepaliveTimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "keepalive_time_seconds"),
+			"Negotiated BGP keepalive time in seconds.",
+			[]string{"name"},
+			nil,
+		),
+		sessionPrefixCountDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "prefix_count"),
+			"Number of prefixes received in the BGP session.",
+			[]string{"name"},
+			nil,
+		),
+		sessionMessagesSentDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "messages_sent_total"),
+			"Total number of BGP messages sent in the session.",
+			[]string{"name"},
+			nil,
+		),
+		sessionMessagesReceivedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "messages_received_total"),
+			"Total number of BGP messages received in the session.",
+			[]string{"name"},
+			nil,
+		),
+		sessionBytesSentDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "sent_bytes_total"),
+			"Total number of bytes sent in the BGP session.",
+			[]string{"name"},
+			nil,
+		),
+		sessionBytesReceivedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "received_bytes_total"),
+			"Total number of bytes received in the BGP session.",
+			[]string{"name"},
+			nil,
+		),
+		sessionReceivedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "received_prefixes"),
+			"Number of prefixes received in the BGP session by address family (RouterOS 7 only).",
+			[]string{"name", "afi"},
+			nil,
+		),
+		sessionAcceptedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "accepted_prefixes"),
+			"Number of received prefixes accepted by the input filter by address family (RouterOS 7 only).",
+			[]string{"name", "afi"},
+			nil,
+		),
+		sessionFilteredDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "filtered_prefixes"),
+			"Number of received prefixes rejected by the input filter by address family (RouterOS 7 only).",
+			[]string{"name", "afi"},
+			nil,
+		),
+		sessionAdvertisedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bgp_session", "advertised_prefixes"),
+			"Number of prefixes advertised in the BGP session by address family.",
+			[]string{"name", "afi"},
+			nil,
+		),
+	}
+}
+
+func (c *bgpCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.peerInfoDesc
+	ch <- c.peerStateDesc
+	ch <- c.peerFSMStateDesc
+	ch <- c.peerFlapsDesc
+	ch <- c.peerLastChangeDesc
+	ch <- c.peerUptimeDesc
+	ch <- c.peerPrefixCountDesc
+	ch <- c.peerUpdatesSentDesc
+	ch <- c.peerUpdatesRecvDesc
+	ch <- c.peerWithdrawsSentDesc
+	ch <- c.peerWithdrawsRecvDesc
+	ch <- c.peerAdvertisedDesc
+	ch <- c.sessionInfoDesc
+	ch <- c.sessionEstablishedDesc
+	ch <- c.sessionFlapsDesc
+	ch <- c.sessionLastChangeDesc
+	ch <- c.sessionUptimeDesc
+	ch <- c.sessionHoldTimeDesc
+	ch <- c.sessionKeepaliveTimeDesc
+	ch <- c.sessionPrefixCountDesc
+	ch <- c.sessionMessagesSentDesc
+	ch <- c.sessionMessagesReceivedDesc
+	ch <- c.sessionBytesSentDesc
+	ch <- c.sessionBytesReceivedDesc
+	ch <- c.sessionReceivedDesc
+	ch <- c.sessionAcceptedDesc
+	ch <- c.sessionFilteredDesc
+	ch <- c.sessionAdvertisedDesc
+}
+
+func (c *bgpCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	bgpStats, err := client.GetBGPPeerStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	sessions, connections, err := client.GetBGPSessionStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	var advertised map[string]map[string]uint64
+	if c.advertisements && (len(bgpStats) > 0 || len(sessions) > 0) {
+		if advertised, err = client.GetBGPAdvertisedPrefixes(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, peer := range bgpStats {
+		disabledLabel := "false"
+		if peer.Disabled {
+			disabledLabel = "true"
+		}
+		ch <- prometheus.MustNewConstMetric(c.peerInfoDesc, prometheus.GaugeValue, 1,
+			peer.Name, peer.Instance, peer.RemoteAddress, peer.RemoteAS, peer.LocalAddress, peer.LocalRole, peer.RemoteRole, disabledLabel,
+		)
+
+		stateValue := 0.0
+		if peer.State == "established" {
+			stateValue = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.peerStateDesc, prometheus.GaugeValue, stateValue, peer.Name, peer.State)
+
+		state := bgpFSMState(peer.State)
+		c.collectFSMState(ch, peer.Name, state)
+
+		flaps, lastChange := bgpSessions.observe(client.Target()+"|peer|"+peer.Name, state == "established", peer.Uptime)
+		ch <- prometheus.MustNewConstMetric(c.peerFlapsDesc, prometheus.CounterValue, float64(flaps), peer.Name)
+		if !lastChange.IsZero() {
+			ch <- prometheus.MustNewConstMetric(c.peerLastChangeDesc, prometheus.GaugeValue, float64(lastChange.Unix()), peer.Name)
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.peerUptimeDesc, prometheus.GaugeValue, peer.Uptime.Seconds(), peer.Name)
+		ch <- prometheus.MustNewConstMetric(c.peerPrefixCountDesc, prometheus.GaugeValue, float64(peer.PrefixCount), peer.Name)
+		ch <- prometheus.MustNewConstMetric(c.peerUpdatesSentDesc, prometheus.CounterValue, float64(peer.UpdatesSent), peer.Name)
+		ch <- prometheus.MustNewConstMetric(c.peerUpdatesRecvDesc, prometheus.CounterValue, float64(peer.UpdatesRecv), peer.Name)
+		ch <- prometheus.MustNewConstMetric(c.peerWithdrawsSentDesc, prometheus.CounterValue, float64(peer.WithdrawsSent), peer.Name)
+		ch <- prometheus.MustNewConstMetric(c.peerWithdrawsRecvDesc, prometheus.CounterValue, float64(peer.WithdrawsRecv), peer.Name)
+
+		if advertised != nil {
+			for _, afi := range addressFamilies(peer.AddressFamilies, advertised[peer.Name]) {
+				ch <- prometheus.MustNewConstMetric(c.peerAdvertisedDesc, prometheus.GaugeValue, float64(advertised[peer.Name][afi]), peer.Name, afi)
+			}
+		}
+	}
+
+	for _, session := range sessions {
+		ch <- prometheus.MustNewConstMetric(c.sessionInfoDesc, prometheus.GaugeValue, 1,
+			session.Name, session.Connection, session.Template, session.RemoteAddress, session.RemoteAS, session.RemoteID,
+			session.LocalAddress, session.LocalAS, session.LocalID, session.LocalRole, session.AddressFamilies,
+		)
+
+		established := 0.0
+		if session.Established {
+			established = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.sessionEstablishedDesc, prometheus.GaugeValue, established, session.Name)
+
+		flaps, lastChange := bgpSessions.observe(client.Target()+"|session|"+session.Name, session.Established, session.Uptime)
+		ch <- prometheus.MustNewConstMetric(c.sessionFlapsDesc, prometheus.CounterValue, float64(flaps), session.Name)
+		if !lastChange.IsZero() {
+			ch <- prometheus.MustNewConstMetric(c.sessionLastChangeDesc, prometheus.GaugeValue, float64(lastChange.Unix()), session.Name)
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.sessionUptimeDesc, prometheus.GaugeValue, session.Uptime.Seconds(), session.Name)
+		ch <- prometheus.MustNewConstMetric(c.sessionHoldTimeDesc, prometheus.GaugeValue, session.HoldTime.Seconds(), session.Name)
+		ch <- prometheus.MustNewConstMetric(c.sessionKeepaliveTimeDesc, prometheus.GaugeValue, session.KeepaliveTime.Seconds(), session.Name)
+		ch <- prometheus.MustNewConstMetric(c.sessionPrefixCountDesc, prometheus.GaugeValue, float64(session.PrefixCount), session.Name)
+		ch <- prometheus.MustNewConstMetric(c.sessionMessagesSentDesc, prometheus.CounterValue, float64(session.MessagesSent), session.Name)
+		ch <- prometheus.MustNewConstMetric(c.sessionMessagesReceivedDesc, prometheus.CounterValue, float64(session.MessagesReceived), session.Name)
+		ch <- prometheus.MustNewConstMetric(c.sessionBytesSentDesc, prometheus.CounterValue, float64(session.BytesSent), session.Name)
+		ch <- prometheus.MustNewConstMetric(c.sessionBytesReceivedDesc, prometheus.CounterValue, float64(session.BytesReceived), session.Name)
+
+		for afi, prefixes := range session.Prefixes {
+			ch <- prometheus.MustNewConstMetric(c.sessionReceivedDesc, prometheus.GaugeValue, float64(prefixes.Received), session.Name, afi)
+			ch <- prometheus.MustNewConstMetric(c.sessionAcceptedDesc, prometheus.GaugeValue, float64(prefixes.Accepted), session.Name, afi)
+			ch <- prometheus.MustNewConstMetric(c.sessionFilteredDesc, prometheus.GaugeValue, float64(prefixes.Filtered), session.Name, afi)
+		}
+		if advertised != nil {
+			for _, afi := range addressFamilies(session.AddressFamilies, advertised[session.Name]) {
+				ch <- prometheus.MustNewConstMetric(c.sessionAdvertisedDesc, prometheus.GaugeValue, float64(advertised[session.Name][afi]), session.Name, afi)
+			}
+		}
+	}
+
+	for _, conn := range connections {
+		c.collectFSMState(ch, conn.Name, bgpConnectionFSMState(conn, sessions))
+	}
+	return nil
+}
+
+// collectFSMState sends the FSM state-set of a peer or connection.
+func (c *bgpCollector) collectFSMState(ch chan<- prometheus.Metric, name, state string) {
+	for _, s := range bgpFSMStates {
+		value := 0.0
+		if s == state {
+			value = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.peerFSMStateDesc, prometheus.GaugeValue, value, name, s)
+	}
+}
+
+// addressFamilies returns the label values of the address families configured
+// for a peer and of those it was advertised prefixes for, so that families
+// without advertisements are reported as zero.
+func addressFamilies(configured string, advertised map[string]uint64) []string {
+	var afis []string
+	for _, afi := range strings.Split(configured, ",") {
+		if afi != "" {
+			afis = append(afis, mikrotik.AddressFamilyLabel(afi))
+		}
+	}
+	for afi := range advertised {
+		afis = append(afis, afi)
+	}
+	slices.Sort(afis)
+	return slices.Compact(afis)
+}
@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/taihen/ros-exporter/pkg/mikrotik"
+)
+
+// bgpFSMStates are the states of the BGP finite state machine (RFC 4271),
+// reported as one series each by the state-set metric.
+var bgpFSMStates = []string{"idle", "connect", "active", "opensent", "openconfirm", "established"}
+
+// bgpFSMState normalizes a state as printed by RouterOS, e.g. "Established"
+// or "open-sent", to one of bgpFSMStates. Unknown states are returned as is.
+func bgpFSMState(state string) string {
+	return strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(state))
+}
+
+// bgpConnectionFSMState derives the FSM state of a RouterOS 7 connection,
+// which RouterOS does not report, from the sessions of the connection:
+//
+//   - established if one of its sessions is established;
+//   - openconfirm if a session received messages, opensent if it only sent
+//     messages and connect if it exchanged none, as the OPEN messages are the
+//     first of a session;
+//   - without a session, idle if the connection is disabled, active if it only
+//     listens for the remote and connect otherwise.
+//
+// The states of sessions that are not established are approximations, as the
+// message counters also count the messages of a previous attempt.
+func bgpConnectionFSMState(conn mikrotik.BGPConnection, sessions []mikrotik.BGPSessionStat) string {
+	state := ""
+	for _, session := range sessions {
+		if session.Connection != conn.Name {
+			continue
+		}
+		s := "connect"
+		switch {
+		case session.Established:
+			s = "established"
+		case session.MessagesReceived > 0:
+			s = "openconfirm"
+		case session.MessagesSent > 0:
+			s = "opensent"
+		}
+		if slices.Index(bgpFSMStates, s) > slices.Index(bgpFSMStates, state) {
+			state = s
+		}
+	}
+	switch {
+	case state != "":
+		return state
+	case conn.Disabled:
+		return "idle"
+	case !conn.Connect:
+		return "active"
+	}
+	return "connect"
+}
+
+// bgpHistoryExpiry is how long a session that is no longer reported is kept.
+const bgpHistoryExpiry = 24 * time.Hour
+
+// bgpSessionHistory is what the exporter remembers of a session between scrapes.
+type bgpSessionHistory struct {
+	established bool
+	uptime      time.Duration
+	flaps       uint64
+	lastChange  time.Time
+	lastSeen    time.Time
+}
+
+// bgpHistory tracks BGP peers and sessions across scrapes to count flaps and
+// remember when their state last changed, which RouterOS does not report.
+type bgpHistory struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	sessions map[string]*bgpSessionHistory
+	pruned   time.Time
+}
+
+var bgpSessions = &bgpHistory{
+	now:      time.Now,
+	sessions: make(map[string]*bgpSessionHistory),
+}
+
+// SetClock replaces the clock that dates the state changes of BGP peers and
+// sessions, so that tests comparing scrapes do not depend on the wall clock.
+// It returns a function restoring the previous clock.
+func SetClock(now func() time.Time) (restore func()) {
+	bgpSessions.mu.Lock()
+	defer bgpSessions.mu.Unlock()
+	old := bgpSessions.now
+	bgpSessions.now = now
+	return func() {
+		bgpSessions.mu.Lock()
+		defer bgpSessions.mu.Unlock()
+		bgpSessions.now = old
+	}
+}
+
+// observe records the state of a session and returns the number of flaps seen
+// so far and the time of the last state change, which is zero if unknown.
+//
+// A flap is a session leaving the established state, or an uptime lower than
+// at the previous scrape, i.e. the session went down and came back between
+// scrapes. Counting starts at the first scrape that sees the session.
+func (h *bgpHistory) observe(key string, established bool, uptime time.Duration) (flaps uint64, lastChange time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	s, ok := h.sessions[key]
+	if !ok {
+		s = &bgpSessionHistory{}
+		h.sessions[key] = s
+	} else if s.established && (!established || uptime < s.uptime) {
+		s.flaps++
+		s.lastChange = now
+	} else if !s.established && established {
+		s.lastChange = now
+	}
+
+	// The uptime dates the last change more precisely than the scrape interval.
+	if established && uptime > 0 {
+		s.lastChange = now.Add(-uptime).Truncate(time.Second)
+	}
+	s.established = established
+	s.uptime = uptime
+	s.lastSeen = now
+
+	if now.Sub(h.pruned) > time.Hour {
+		for k, other := range h.sessions {
+			if now.Sub(other.lastSeen) > bgpHistoryExpiry {
+				delete(h.sessions, k)
+			}
+		}
+		h.pruned = now
+	}
+	return s.flaps, s.lastChange
+}
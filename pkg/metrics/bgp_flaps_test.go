@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/taihen/ros-exporter/pkg/mikrotik"
+)
+
+func TestBGPHistoryFlaps(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	now := start
+	h := &bgpHistory{
+		now:      func() time.Time { return now },
+		sessions: make(map[string]*bgpSessionHistory),
+	}
+
+	// Scrapes are a minute apart.
+	for _, step := range []struct {
+		name        string
+		established bool
+		uptime      time.Duration
+		wantFlaps   uint64
+		wantChange  time.Time
+	}{
+		{"first scrape", true, time.Hour, 0, start.Add(-time.Hour)},
+		{"still up", true, time.Hour + time.Minute, 0, start.Add(-time.Hour)},
+		{"reset between scrapes", true, 30 * time.Second, 1, start.Add(90 * time.Second)},
+		{"down", false, 0, 2, start.Add(3 * time.Minute)},
+		{"still down", false, 0, 2, start.Add(3 * time.Minute)},
+		{"up again", true, 10 * time.Second, 2, start.Add(5*time.Minute - 10*time.Second)},
+	} {
+		flaps, lastChange := h.observe("router|peer|upstream", step.established, step.uptime)
+		if flaps != step.wantFlaps || !lastChange.Equal(step.wantChange) {
+			t.Errorf("%s: got %d flaps, last change %s; want %d, %s", step.name, flaps, lastChange, step.wantFlaps, step.wantChange)
+		}
+		now = now.Add(time.Minute)
+	}
+}
+
+func TestBGPFSMState(t *testing.T) {
+	for in, want := range map[string]string{
+		"established": "established",
+		"Established": "established",
+		"open-sent":   "opensent",
+		"OpenConfirm": "openconfirm",
+		"":            "",
+	} {
+		if got := bgpFSMState(in); got != want {
+			t.Errorf("bgpFSMState(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBGPConnectionFSMState(t *testing.T) {
+	sessions := []mikrotik.BGPSessionStat{
+		{Name: "upstream-1", Connection: "upstream", Established: true},
+		{Name: "rr-1", Connection: "rr", MessagesSent: 1},
+		{Name: "ix-1", Connection: "ix", MessagesSent: 1, MessagesReceived: 1},
+		{Name: "ix-2", Connection: "ix"},
+		{Name: "dc-1", Connection: "dc"},
+		{Name: "listen-1", Connection: "listen", MessagesSent: 2},
+		{Name: "listen-2", Connection: "listen", Established: true},
+	}
+	for _, tc := range []struct {
+		conn mikrotik.BGPConnection
+		want string
+	}{
+		{mikrotik.BGPConnection{Name: "upstream", Connect: true}, "established"},
+		{mikrotik.BGPConnection{Name: "rr", Connect: true}, "opensent"},
+		{mikrotik.BGPConnection{Name: "ix", Connect: true}, "openconfirm"},
+		{mikrotik.BGPConnection{Name: "dc", Connect: true}, "connect"},
+		{mikrotik.BGPConnection{Name: "listen"}, "established"},
+		{mikrotik.BGPConnection{Name: "backup", Connect: true, Disabled: true}, "idle"},
+		{mikrotik.BGPConnection{Name: "passive"}, "active"},
+		{mikrotik.BGPConnection{Name: "down", Connect: true}, "connect"},
+	} {
+		if got := bgpConnectionFSMState(tc.conn, sessions); got != tc.want {
+			t.Errorf("bgpConnectionFSMState(%+v) = %q, want %q", tc.conn, got, tc.want)
+		}
+	}
+}
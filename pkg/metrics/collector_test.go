@@ -0,0 +1,196 @@
+package metrics
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"github.com/taihen/ros-exporter/pkg/mikrotik"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// collectAll enables every registered sub-collector.
+func collectAll() map[string]bool {
+	enabled := make(map[string]bool)
+	for _, name := range CollectorNames() {
+		enabled[name] = true
+	}
+	return enabled
+}
+
+// disablePackages marks the named packages as disabled in the package list of a fixture.
+func disablePackages(names ...string) func(*mikrotik.Fixture) {
+	return func(f *mikrotik.Fixture) {
+		for _, cmd := range f.Commands {
+			if cmd.Command != "/system/package/print" {
+				continue
+			}
+			for _, re := range cmd.Replies {
+				if slices.Contains(names, re["name"]) {
+					re["disabled"] = "true"
+				}
+			}
+		}
+	}
+}
+
+// trapCommand makes the router answer the command with args with a trap.
+func trapCommand(command string, args ...string) func(*mikrotik.Fixture) {
+	return func(f *mikrotik.Fixture) {
+		f.Commands = append(f.Commands, mikrotik.FixtureCommand{Command: command, Args: args, Trap: "interrupted"})
+	}
+}
+
+// breakPools gives pppoe-pool invalid ranges, makes counting the used
+// addresses of dhcp-pool fail and adds a working guest-pool.
+func breakPools(f *mikrotik.Fixture) {
+	for i := range f.Commands {
+		cmd := &f.Commands[i]
+		switch {
+		case cmd.Command == "/ip/pool/print":
+			for _, re := range cmd.Replies {
+				if re["name"] == "pppoe-pool" {
+					re["ranges"] = "10.10.0.2-10.10"
+				}
+			}
+			cmd.Replies = append(cmd.Replies, map[string]string{"name": "guest-pool", "ranges": "172.16.0.0/24", "next-pool": "none"})
+		case cmd.Command == "/ip/pool/used/print" && slices.Contains(cmd.Args, "?pool=dhcp-pool"):
+			cmd.Trap = "interrupted"
+		}
+	}
+	f.Commands = append(f.Commands, mikrotik.FixtureCommand{
+		Command: "/ip/pool/used/print",
+		Args:    []string{"=count-only=", "?pool=guest-pool"},
+		Done:    map[string]string{"ret": "5"},
+	})
+}
+
+func TestCollectorGolden(t *testing.T) {
+	// BGP state change timestamps are derived from the scrape time.
+	t.Cleanup(SetClock(func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }))
+
+	for _, tc := range []struct {
+		name    string
+		fixture string
+		enabled map[string]bool
+		modify  func(*mikrotik.Fixture)
+		opts    Options
+	}{
+		// RouterOS 6.48 with every collector enabled.
+		{"routeros-6.48", "routeros-6.48.yml", collectAll(), nil, Options{}},
+		// The same router with the optional collectors left at their defaults,
+		// i.e. wireless, BGP and PPP disabled.
+		{"routeros-6.48-defaults", "routeros-6.48.yml", nil, nil, Options{}},
+		// The same router with the routing, ppp, wireless and dhcp packages disabled:
+		// their menus are not queried even though the fixture answers them.
+		{"routeros-6.48-packages-disabled", "routeros-6.48.yml", collectAll(), disablePackages("routing", "ppp", "wireless", "dhcp"), Options{}},
+		// The same router failing the version probe: the system metrics are
+		// still exported, only without mikrotik_routeros_info.
+		{"routeros-6.48-no-version", "routeros-6.48.yml", map[string]bool{"system": true}, trapCommand("/system/resource/print", "=.proplist=version"), Options{}},
+		// The same router counting the prefixes advertised to its BGP peers.
+		{"routeros-6.48-bgp-advertisements", "routeros-6.48.yml", map[string]bool{"bgp": true}, nil, Options{BGPAdvertisements: true}},
+		// The same router exporting only the firewall rules marked for monitoring.
+		{"routeros-6.48-firewall-include", "routeros-6.48.yml", map[string]bool{"firewall": true}, nil, Options{FirewallCommentInclude: regexp.MustCompile(`^monitor:`)}},
+		// The same router counting only the blocklists.
+		{"routeros-6.48-addresslist-include", "routeros-6.48.yml", map[string]bool{"addresslist": true}, nil, Options{AddressListInclude: regexp.MustCompile(`^(blocklist|ddos)$`)}},
+		// The same router exporting every DHCP lease.
+		{"routeros-6.48-dhcp-leases", "routeros-6.48.yml", map[string]bool{"dhcp": true}, nil, Options{DHCPLeases: true}},
+		// The same router with broken pools: the DHCP and pool collectors
+		// leave them out and export the rest.
+		{"routeros-6.48-bad-pools", "routeros-6.48.yml", map[string]bool{"dhcp": true, "pool": true}, breakPools, Options{}},
+		// RouterOS 7 without the v6 BGP menus and the wireless package.
+		{"routeros-7.12", "routeros-7.12.yml", collectAll(), nil, Options{}},
+		// The same router counting the prefixes advertised in its BGP sessions.
+		{"routeros-7.12-bgp-advertisements", "routeros-7.12.yml", map[string]bool{"bgp": true}, nil, Options{BGPAdvertisements: true}},
+		// A virtual router without /system/health.
+		{"routeros-chr-no-health", "routeros-chr-no-health.yml", nil, nil, Options{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fixture, err := mikrotik.LoadFixture(filepath.Join("testdata", tc.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.modify != nil {
+				tc.modify(fixture)
+			}
+			client := mikrotik.NewClient(tc.name, "", "", 5*time.Second)
+			client.Backend = mikrotik.BackendReplay
+			client.Fixture = fixture
+
+			got := exposition(t, NewMikrotikCollector(t.Context(), client, tc.enabled, tc.opts))
+
+			golden := filepath.Join("testdata", tc.name+".prom")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("exposition differs from %s (run go test -update if the change is intended):\n%s", golden, diff(string(want), string(got)))
+			}
+		})
+	}
+}
+
+// exposition collects c and returns the text exposition without the duration
+// metrics, which vary between runs.
+func exposition(t *testing.T, c prometheus.Collector) []byte {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	for _, family := range families {
+		if strings.HasSuffix(family.GetName(), "duration_seconds") {
+			continue
+		}
+		if _, err := expfmt.MetricFamilyToText(&buf, family); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// diff returns the lines only present in want (-) or got (+).
+func diff(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	inWant := make(map[string]bool, len(wantLines))
+	for _, line := range wantLines {
+		inWant[line] = true
+	}
+	inGot := make(map[string]bool, len(gotLines))
+	for _, line := range gotLines {
+		inGot[line] = true
+	}
+
+	var b strings.Builder
+	for _, line := range wantLines {
+		if !inGot[line] {
+			b.WriteString("- " + line + "\n")
+		}
+	}
+	for _, line := range gotLines {
+		if !inWant[line] {
+			b.WriteString("+ " + line + "\n")
+		}
+	}
+	return b.String()
+}
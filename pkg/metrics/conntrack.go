@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("conntrack", false, newConntrackCollector)
+}
+
+type conntrackCollector struct {
+	infoDesc            *prometheus.Desc
+	activeDesc          *prometheus.Desc
+	entriesDesc         *prometheus.Desc
+	maxEntriesDesc      *prometheus.Desc
+	protocolEntriesDesc *prometheus.Desc
+	tcpStateEntriesDesc *prometheus.Desc
+	timeoutSecondsDesc  *prometheus.Desc
+}
+
+func newConntrackCollector(Options) SubCollector {
+	return &conntrackCollector{
+		infoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "conntrack", "info"),
+			"Connection tracking settings, enabled is yes, no or auto.",
+			[]string{"enabled"},
+			nil,
+		),
+		activeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "conntrack", "active"),
+			"Whether IPv4 connection tracking is running (1) or not (0).",
+			nil,
+			nil,
+		),
+		entriesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "conntrack", "entries"),
+			"Number of entries in the connection tracking table.",
+			nil,
+			nil,
+		),
+		maxEntriesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "conntrack", "max_entries"),
+			"Maximum number of entries in the connection tracking table.",
+			nil,
+			nil,
+		),
+		protocolEntriesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "conntrack", "protocol_entries"),
+			"Number of entries in the connection tracking table by protocol.",
+			[]string{"protocol"},
+			nil,
+		),
+		tcpStateEntriesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "conntrack", "tcp_state_entries"),
+			"Number of TCP entries in the connection tracking table by state.",
+			[]string{"state"},
+			nil,
+		),
+		timeoutSecondsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "conntrack", "timeout_seconds"),
+			"Configured connection tracking timeout in seconds.",
+			[]string{"timeout"},
+			nil,
+		),
+	}
+}
+
+func (c *conntrackCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.infoDesc
+	ch <- c.activeDesc
+	ch <- c.entriesDesc
+	ch <- c.maxEntriesDesc
+	ch <- c.protocolEntriesDesc
+	ch <- c.tcpStateEntriesDesc
+	ch <- c.timeoutSecondsDesc
+}
+
+func (c *conntrackCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	ct, err := client.GetConnectionTracking(ctx)
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.infoDesc, prometheus.GaugeValue, 1, ct.Enabled)
+	active := 0.0
+	if ct.Active {
+		active = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(c.activeDesc, prometheus.GaugeValue, active)
+	ch <- prometheus.MustNewConstMetric(c.entriesDesc, prometheus.GaugeValue, float64(ct.TotalEntries))
+	if ct.MaxEntries > 0 {
+		ch <- prometheus.MustNewConstMetric(c.maxEntriesDesc, prometheus.GaugeValue, float64(ct.MaxEntries))
+	}
+	for protocol, n := range ct.Protocols {
+		ch <- prometheus.MustNewConstMetric(c.protocolEntriesDesc, prometheus.GaugeValue, float64(n), protocol)
+	}
+	for state, n := range ct.TCPStates {
+		ch <- prometheus.MustNewConstMetric(c.tcpStateEntriesDesc, prometheus.GaugeValue, float64(n), state)
+	}
+	for name, d := range ct.Timeouts {
+		ch <- prometheus.MustNewConstMetric(c.timeoutSecondsDesc, prometheus.GaugeValue, d.Seconds(), name)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/taihen/ros-exporter/pkg/mikrotik"
+)
+
+func init() {
+	registerCollector("dhcp", false, newDHCPCollector)
+}
+
+type dhcpCollector struct {
+	leases bool
+
+	infoDesc         *prometheus.Desc
+	leaseTimeDesc    *prometheus.Desc
+	leasesDesc       *prometheus.Desc
+	poolSizeDesc     *prometheus.Desc
+	poolUsedDesc     *prometheus.Desc
+	leaseInfoDesc    *prometheus.Desc
+	leaseExpiresDesc *prometheus.Desc
+}
+
+func newDHCPCollector(opts Options) SubCollector {
+	return &dhcpCollector{
+		leases: opts.DHCPLeases,
+
+		infoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dhcp_server", "info"),
+			"DHCP server information.",
+			[]string{"server", "interface", "address_pool", "disabled"},
+			nil,
+		),
+		leaseTimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dhcp_server", "lease_time_seconds"),
+			"Configured lease time of the DHCP server in seconds.",
+			[]string{"server"},
+			nil,
+		),
+		leasesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dhcp_server", "leases"),
+			"Number of leases of the DHCP server by status.",
+			[]string{"server", "status"},
+			nil,
+		),
+		poolSizeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dhcp_server", "pool_addresses"),
+			"Number of addresses in the address pool of the DHCP server.",
+			[]string{"server", "pool"},
+			nil,
+		),
+		poolUsedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dhcp_server", "pool_used_addresses"),
+			"Number of used addresses in the address pool of the DHCP server.",
+			[]string{"server", "pool"},
+			nil,
+		),
+		leaseInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dhcp_lease", "info"),
+			"DHCP lease information.",
+			[]string{"server", "address", "mac_address", "host_name", "status", "dynamic"},
+			nil,
+		),
+		leaseExpiresDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "dhcp_lease", "expires_after_seconds"),
+			"Time until the DHCP lease expires in seconds.",
+			[]string{"server", "address", "mac_address"},
+			nil,
+		),
+	}
+}
+
+func (c *dhcpCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.infoDesc
+	ch <- c.leaseTimeDesc
+	ch <- c.leasesDesc
+	ch <- c.poolSizeDesc
+	ch <- c.poolUsedDesc
+	ch <- c.leaseInfoDesc
+	ch <- c.leaseExpiresDesc
+}
+
+func (c *dhcpCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	servers, err := client.GetDHCPServers(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, server := range servers {
+		ch <- prometheus.MustNewConstMetric(c.infoDesc, prometheus.GaugeValue, 1, server.Name, server.Interface, server.AddressPool, strconv.FormatBool(server.Disabled))
+		ch <- prometheus.MustNewConstMetric(c.leaseTimeDesc, prometheus.GaugeValue, server.LeaseTime.Seconds(), server.Name)
+		for _, status := range mikrotik.DHCPLeaseStatuses {
+			ch <- prometheus.MustNewConstMetric(c.leasesDesc, prometheus.GaugeValue, float64(server.Leases[status]), server.Name, status)
+		}
+		if server.PoolSize > 0 {
+			ch <- prometheus.MustNewConstMetric(c.poolSizeDesc, prometheus.GaugeValue, float64(server.PoolSize), server.Name, server.AddressPool)
+			ch <- prometheus.MustNewConstMetric(c.poolUsedDesc, prometheus.GaugeValue, float64(server.PoolUsed), server.Name, server.AddressPool)
+		}
+	}
+
+	if !c.leases {
+		return nil
+	}
+	leases, err := client.GetDHCPLeases(ctx)
+	if err != nil {
+		return err
+	}
+	for _, lease := range leases {
+		ch <- prometheus.MustNewConstMetric(c.leaseInfoDesc, prometheus.GaugeValue, 1, lease.Server, lease.Address, lease.MACAddress, lease.HostName, lease.Status, strconv.FormatBool(lease.Dynamic))
+		if lease.ExpiresAfter > 0 {
+			ch <- prometheus.MustNewConstMetric(c.leaseExpiresDesc, prometheus.GaugeValue, lease.ExpiresAfter.Seconds(), lease.Server, lease.Address, lease.MACAddress)
+		}
+	}
+	return nil
+}
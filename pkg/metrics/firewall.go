@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("firewall", false, newFirewallCollector)
+}
+
+type firewallCollector struct {
+	opts Options
+
+	bytesDesc   *prometheus.Desc
+	packetsDesc *prometheus.Desc
+}
+
+func newFirewallCollector(opts Options) SubCollector {
+	labels := []string{"family", "table", "chain", "action", "comment", "rule"}
+	return &firewallCollector{
+		opts: opts,
+		bytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "firewall_rule", "bytes_total"),
+			"Total number of bytes matched by the firewall rule.",
+			labels,
+			nil,
+		),
+		packetsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "firewall_rule", "packets_total"),
+			"Total number of packets matched by the firewall rule.",
+			labels,
+			nil,
+		),
+	}
+}
+
+func (c *firewallCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.bytesDesc
+	ch <- c.packetsDesc
+}
+
+func (c *firewallCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	rules, err := client.GetFirewallRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if c.opts.FirewallCommentInclude != nil && !c.opts.FirewallCommentInclude.MatchString(rule.Comment) {
+			continue
+		}
+		labels := []string{rule.Family, rule.Table, rule.Chain, rule.Action, rule.Comment, rule.Key}
+		ch <- prometheus.MustNewConstMetric(c.bytesDesc, prometheus.CounterValue, float64(rule.Bytes), labels...)
+		ch <- prometheus.MustNewConstMetric(c.packetsDesc, prometheus.CounterValue, float64(rule.Packets), labels...)
+	}
+	return nil
+}
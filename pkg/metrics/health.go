@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("health", true, newHealthCollector)
+}
+
+type healthCollector struct {
+	temperatureDesc      *prometheus.Desc
+	boardTemperatureDesc *prometheus.Desc
+	voltageDesc          *prometheus.Desc
+	currentDesc          *prometheus.Desc
+	powerConsumedDesc    *prometheus.Desc
+	fanSpeedDesc         *prometheus.Desc
+}
+
+func newHealthCollector(Options) SubCollector {
+	return &healthCollector{
+		temperatureDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "health", "temperature_celsius"),
+			"System temperature (often CPU) in degrees Celsius.",
+			[]string{"sensor"},
+			nil,
+		),
+		boardTemperatureDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "health", "board_temperature_celsius"),
+			"Board temperature in degrees Celsius.",
+			nil, nil,
+		),
+		voltageDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "health", "voltage_volts"),
+			"System voltage.",
+			nil, nil,
+		),
+		currentDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "health", "current_amperes"),
+			"System current draw in Amperes (if available).",
+			nil, nil,
+		),
+		powerConsumedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "health", "power_consumed_watts"),
+			"System power consumption in Watts (if available).",
+			nil, nil,
+		),
+		fanSpeedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "health", "fan_speed_rpm"),
+			"Fan speed in RPM (if available).",
+			[]string{"fan"},
+			nil,
+		),
+	}
+}
+
+func (c *healthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.temperatureDesc
+	ch <- c.boardTemperatureDesc
+	ch <- c.voltageDesc
+	ch <- c.currentDesc
+	ch <- c.powerConsumedDesc
+	ch <- c.fanSpeedDesc
+}
+
+func (c *healthCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	health, err := client.GetSystemHealth(ctx)
+	if err != nil {
+		return err
+	}
+	if health == nil {
+		log.Printf("Info: System health metrics not available or not supported on %s.", client.Target())
+		return nil
+	}
+
+	if health.Temperature != 0 {
+		ch <- prometheus.MustNewConstMetric(c.temperatureDesc, prometheus.GaugeValue, health.Temperature, "cpu")
+	}
+	if health.BoardTemperature != 0 && health.BoardTemperature != health.Temperature {
+		ch <- prometheus.MustNewConstMetric(c.temperatureDesc, prometheus.GaugeValue, health.BoardTemperature, "board")
+	}
+	if health.Voltage != 0 {
+		ch <- prometheus.MustNewConstMetric(c.voltageDesc, prometheus.GaugeValue, health.Voltage)
+	}
+	if health.Current != 0 {
+		ch <- prometheus.MustNewConstMetric(c.currentDesc, prometheus.GaugeValue, health.Current)
+	}
+	if health.PowerConsumed != 0 {
+		ch <- prometheus.MustNewConstMetric(c.powerConsumedDesc, prometheus.GaugeValue, health.PowerConsumed)
+	}
+	if health.FanSpeed != 0 {
+		ch <- prometheus.MustNewConstMetric(c.fanSpeedDesc, prometheus.GaugeValue, float64(health.FanSpeed), "fan1")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("interface", true, newInterfaceCollector)
+}
+
+type interfaceCollector struct {
+	infoDesc      *prometheus.Desc
+	rxBytesDesc   *prometheus.Desc
+	txBytesDesc   *prometheus.Desc
+	rxPacketsDesc *prometheus.Desc
+	txPacketsDesc *prometheus.Desc
+	rxErrorsDesc  *prometheus.Desc
+	txErrorsDesc  *prometheus.Desc
+	rxDropsDesc   *prometheus.Desc
+	txDropsDesc   *prometheus.Desc
+}
+
+func newInterfaceCollector(Options) SubCollector {
+	return &interfaceCollector{
+		infoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "interface", "info"),
+			"Interface information (admin status, running status).",
+			[]string{"name", "type", "comment", "mac_address"},
+			nil,
+		),
+		rxBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "interface", "receive_bytes_total"),
+			"Total number of bytes received.",
+			[]string{"name"},
+			nil,
+		),
+		txBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "interface", "transmit_bytes_total"),
+			"Total number of bytes transmitted.",
+			[]string{"name"},
+			nil,
+		),
+		rxPacketsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "interface", "receive_packets_total"),
+			"Total number of packets received.",
+			[]string{"name"},
+			nil,
+		),
+		txPacketsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "interface", "transmit_packets_total"),
+			"Total number of packets transmitted.",
+			[]string{"name"},
+			nil,
+		),
+		rxErrorsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "interface", "receive_errors_total"),
+			"Total number of receive errors.",
+			[]string{"name"},
+			nil,
+		),
+		txErrorsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "interface", "transmit_errors_total"),
+			"Total number of transmit errors.",
+			[]string{"name"},
+			nil,
+		),
+		rxDropsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "interface", "receive_drops_total"),
+			"Total number of received packets dropped.",
+			[]string{"name"},
+			nil,
+		),
+		txDropsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "interface", "transmit_drops_total"),
+			"Total number of transmitted packets dropped.",
+			[]string{"name"},
+			nil,
+		),
+	}
+}
+
+func (c *interfaceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.infoDesc
+	ch <- c.rxBytesDesc
+	ch <- c.txBytesDesc
+	ch <- c.rxPacketsDesc
+	ch <- c.txPacketsDesc
+	ch <- c.rxErrorsDesc
+	ch <- c.txErrorsDesc
+	ch <- c.rxDropsDesc
+	ch <- c.txDropsDesc
+}
+
+func (c *interfaceCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	interfaceStats, err := client.GetInterfaceStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, iface := range interfaceStats {
+		opStatus := 0.0
+		if iface.Running {
+			opStatus = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.infoDesc, prometheus.GaugeValue, opStatus,
+			iface.Name, iface.Type, iface.Comment, iface.MACAddress,
+		)
+
+		ch <- prometheus.MustNewConstMetric(c.rxBytesDesc, prometheus.CounterValue, float64(iface.RxBytes), iface.Name)
+		ch <- prometheus.MustNewConstMetric(c.txBytesDesc, prometheus.CounterValue, float64(iface.TxBytes), iface.Name)
+		ch <- prometheus.MustNewConstMetric(c.rxPacketsDesc, prometheus.CounterValue, float64(iface.RxPackets), iface.Name)
+		ch <- prometheus.MustNewConstMetric(c.txPacketsDesc, prometheus.CounterValue, float64(iface.TxPackets), iface.Name)
+		ch <- prometheus.MustNewConstMetric(c.rxErrorsDesc, prometheus.CounterValue, float64(iface.RxErrors), iface.Name)
+		ch <- prometheus.MustNewConstMetric(c.txErrorsDesc, prometheus.CounterValue, float64(iface.TxErrors), iface.Name)
+		ch <- prometheus.MustNewConstMetric(c.rxDropsDesc, prometheus.CounterValue, float64(iface.RxDrops), iface.Name)
+		ch <- prometheus.MustNewConstMetric(c.txDropsDesc, prometheus.CounterValue, float64(iface.TxDrops), iface.Name)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("ospf", false, newOSPFCollector)
+}
+
+// ospfNeighborStates are the states of an OSPF neighbor (RFC 2328), reported
+// as one series each by the state-set metric.
+var ospfNeighborStates = []string{"down", "attempt", "init", "2-way", "exstart", "exchange", "loading", "full"}
+
+type ospfCollector struct {
+	neighborInfoDesc         *prometheus.Desc
+	neighborStateDesc        *prometheus.Desc
+	neighborAdjacencyDesc    *prometheus.Desc
+	neighborStateChangesDesc *prometheus.Desc
+	instanceInfoDesc         *prometheus.Desc
+	instanceLSACountDesc     *prometheus.Desc
+}
+
+func newOSPFCollector(Options) SubCollector {
+	// OSPFv3 neighbors are addressed by link-local addresses, which are the
+	// same on every interface to the neighbor.
+	neighborLabels := []string{"instance", "router_id", "address", "interface"}
+	return &ospfCollector{
+		neighborInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ospf_neighbor", "info"),
+			"OSPF neighbor information.",
+			append(neighborLabels, "area", "priority", "dr", "bdr"),
+			nil,
+		),
+		neighborStateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ospf_neighbor", "state"),
+			"OSPF neighbor state, 1 for the current state and 0 for the others.",
+			append(neighborLabels, "state"),
+			nil,
+		),
+		neighborAdjacencyDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ospf_neighbor", "adjacency_seconds"),
+			"Time since the adjacency with the OSPF neighbor was formed in seconds.",
+			neighborLabels,
+			nil,
+		),
+		neighborStateChangesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ospf_neighbor", "state_changes_total"),
+			"Number of state changes of the OSPF neighbor reported by the router.",
+			neighborLabels,
+			nil,
+		),
+		instanceInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ospf_instance", "info"),
+			"OSPF instance information.",
+			[]string{"instance", "router_id", "version", "disabled"},
+			nil,
+		),
+		instanceLSACountDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ospf_instance", "lsa_count"),
+			"Number of LSAs in the link-state database of the OSPF instance by LSA type.",
+			[]string{"instance", "type"},
+			nil,
+		),
+	}
+}
+
+func (c *ospfCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.neighborInfoDesc
+	ch <- c.neighborStateDesc
+	ch <- c.neighborAdjacencyDesc
+	ch <- c.neighborStateChangesDesc
+	ch <- c.instanceInfoDesc
+	ch <- c.instanceLSACountDesc
+}
+
+func (c *ospfCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	neighbors, err := client.GetOSPFNeighbors(ctx)
+	if err != nil {
+		return err
+	}
+	instances, err := client.GetOSPFInstances(ctx)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[[4]string]bool, len(neighbors))
+	for _, n := range neighbors {
+		// A second neighbor with the same labels would fail the whole scrape.
+		key := [4]string{n.Instance, n.RouterID, n.Address, n.Interface}
+		if seen[key] {
+			log.Printf("Warning: Skipping duplicate OSPF neighbor %s (%s) on interface '%s' of %s", n.RouterID, n.Address, n.Interface, client.Target())
+			continue
+		}
+		seen[key] = true
+
+		ch <- prometheus.MustNewConstMetric(c.neighborInfoDesc, prometheus.GaugeValue, 1,
+			n.Instance, n.RouterID, n.Address, n.Interface, n.Area, n.Priority, n.DR, n.BDR,
+		)
+
+		state := strings.ToLower(n.State)
+		for _, s := range ospfNeighborStates {
+			value := 0.0
+			if s == state {
+				value = 1.0
+			}
+			ch <- prometheus.MustNewConstMetric(c.neighborStateDesc, prometheus.GaugeValue, value, n.Instance, n.RouterID, n.Address, n.Interface, s)
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.neighborAdjacencyDesc, prometheus.GaugeValue, n.Adjacency.Seconds(), n.Instance, n.RouterID, n.Address, n.Interface)
+		ch <- prometheus.MustNewConstMetric(c.neighborStateChangesDesc, prometheus.CounterValue, float64(n.StateChanges), n.Instance, n.RouterID, n.Address, n.Interface)
+	}
+
+	for _, instance := range instances {
+		ch <- prometheus.MustNewConstMetric(c.instanceInfoDesc, prometheus.GaugeValue, 1, instance.Name, instance.RouterID, instance.Version, strconv.FormatBool(instance.Disabled))
+
+		for lsaType, count := range instance.LSAs {
+			ch <- prometheus.MustNewConstMetric(c.instanceLSACountDesc, prometheus.GaugeValue, float64(count), instance.Name, lsaType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("pool", false, newPoolCollector)
+}
+
+type poolCollector struct {
+	infoDesc *prometheus.Desc
+	sizeDesc *prometheus.Desc
+	usedDesc *prometheus.Desc
+}
+
+func newPoolCollector(Options) SubCollector {
+	labels := []string{"family", "pool"}
+	return &poolCollector{
+		infoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ip_pool", "info"),
+			"IP pool information.",
+			[]string{"family", "pool", "next_pool"},
+			nil,
+		),
+		sizeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ip_pool", "size"),
+			"Number of addresses (IPv4) or delegated prefixes (IPv6) in the IP pool.",
+			labels,
+			nil,
+		),
+		usedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ip_pool", "used"),
+			"Number of used addresses (IPv4) or delegated prefixes (IPv6) of the IP pool.",
+			labels,
+			nil,
+		),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.infoDesc
+	ch <- c.sizeDesc
+	ch <- c.usedDesc
+}
+
+func (c *poolCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	pools, err := client.GetIPPools(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, pool := range pools {
+		ch <- prometheus.MustNewConstMetric(c.infoDesc, prometheus.GaugeValue, 1, pool.Family, pool.Name, pool.NextPool)
+		ch <- prometheus.MustNewConstMetric(c.sizeDesc, prometheus.GaugeValue, float64(pool.Size), pool.Family, pool.Name)
+		ch <- prometheus.MustNewConstMetric(c.usedDesc, prometheus.GaugeValue, float64(pool.Used), pool.Family, pool.Name)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("ppp", false, newPPPCollector)
+}
+
+type pppCollector struct {
+	activeCountDesc *prometheus.Desc
+	userInfoDesc    *prometheus.Desc
+	userUptimeDesc  *prometheus.Desc
+}
+
+func newPPPCollector(Options) SubCollector {
+	return &pppCollector{
+		activeCountDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ppp", "active_users_count"),
+			"Total number of active PPP users.",
+			nil,
+			nil,
+		),
+		userInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ppp_user", "info"),
+			"PPP user session information (1 = active).",
+			[]string{"name", "service", "caller_id", "address", "uptime_text"},
+			nil,
+		),
+		userUptimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ppp_user", "uptime_seconds"),
+			"PPP user session uptime in seconds.",
+			[]string{"name"},
+			nil,
+		),
+	}
+}
+
+func (c *pppCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.activeCountDesc
+	ch <- c.userInfoDesc
+	ch <- c.userUptimeDesc
+}
+
+func (c *pppCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	pppUsers, err := client.GetPPPActiveUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.activeCountDesc, prometheus.GaugeValue, float64(len(pppUsers)))
+
+	for _, user := range pppUsers {
+		ch <- prometheus.MustNewConstMetric(c.userInfoDesc, prometheus.GaugeValue, 1,
+			user.Name, user.Service, user.CallerID, user.Address, user.UptimeStr,
+		)
+		ch <- prometheus.MustNewConstMetric(c.userUptimeDesc, prometheus.GaugeValue, user.Uptime.Seconds(), user.Name)
+	}
+	return nil
+}
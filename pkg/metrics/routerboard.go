@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("routerboard", true, newRouterboardCollector)
+}
+
+type routerboardCollector struct {
+	boardInfoDesc *prometheus.Desc
+}
+
+func newRouterboardCollector(Options) SubCollector {
+	return &routerboardCollector{
+		boardInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "system", "info"),
+			"Non-numeric information about the router board.",
+			[]string{"board_name", "model", "serial_number", "firmware_type", "factory_firmware", "current_firmware", "upgrade_firmware"},
+			nil,
+		),
+	}
+}
+
+func (c *routerboardCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.boardInfoDesc
+}
+
+func (c *routerboardCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	routerboard, err := client.GetRouterboard(ctx)
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.boardInfoDesc, prometheus.GaugeValue, 1,
+		routerboard.BoardName,
+		routerboard.Model,
+		routerboard.SerialNumber,
+		routerboard.FirmwareType,
+		routerboard.FactoryFirmware,
+		routerboard.CurrentFirmware,
+		routerboard.UpgradeFirmware,
+	)
+	return nil
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("routes", false, newRoutesCollector)
+}
+
+type routesCollector struct {
+	routesDesc      *prometheus.Desc
+	tableRoutesDesc *prometheus.Desc
+}
+
+func newRoutesCollector(Options) SubCollector {
+	return &routesCollector{
+		routesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "routes", "count"),
+			"Number of routes by address family, routing table, protocol and whether they are active.",
+			[]string{"afi", "table", "protocol", "active"},
+			nil,
+		),
+		tableRoutesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "routes", "table_count"),
+			"Number of routes of all protocols by address family, routing table and whether they are active.",
+			[]string{"afi", "table", "active"},
+			nil,
+		),
+	}
+}
+
+func (c *routesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.routesDesc
+	ch <- c.tableRoutesDesc
+}
+
+func (c *routesCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	counts, err := client.GetRouteCounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, rc := range counts {
+		if rc.Protocol == "" {
+			ch <- prometheus.MustNewConstMetric(c.tableRoutesDesc, prometheus.GaugeValue, float64(rc.Active), rc.AddressFamily, rc.Table, "true")
+			ch <- prometheus.MustNewConstMetric(c.tableRoutesDesc, prometheus.GaugeValue, float64(rc.Inactive), rc.AddressFamily, rc.Table, "false")
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.routesDesc, prometheus.GaugeValue, float64(rc.Active), rc.AddressFamily, rc.Table, rc.Protocol, "true")
+		ch <- prometheus.MustNewConstMetric(c.routesDesc, prometheus.GaugeValue, float64(rc.Inactive), rc.AddressFamily, rc.Table, rc.Protocol, "false")
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"context"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("system", true, newSystemCollector)
+}
+
+type systemCollector struct {
+	cpuLoadDesc           *prometheus.Desc
+	memoryUsageDesc       *prometheus.Desc
+	totalMemoryDesc       *prometheus.Desc
+	uptimeDesc            *prometheus.Desc
+	storageTotalBytesDesc *prometheus.Desc
+	storageFreeBytesDesc  *prometheus.Desc
+	storageUsedBytesDesc  *prometheus.Desc
+	routerOSInfoDesc      *prometheus.Desc
+}
+
+func newSystemCollector(Options) SubCollector {
+	return &systemCollector{
+		cpuLoadDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "system", "cpu_load_percent"),
+			"Current CPU load percentage.",
+			nil, nil,
+		),
+		memoryUsageDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "system", "memory_usage_bytes"),
+			"Currently used memory in bytes.",
+			nil, nil,
+		),
+		totalMemoryDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "system", "memory_total_bytes"),
+			"Total available memory in bytes.",
+			nil, nil,
+		),
+		uptimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "system", "uptime_seconds"),
+			"System uptime in seconds.",
+			nil, nil,
+		),
+		storageTotalBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "system", "storage_total_bytes"),
+			"Total system storage (HDD) size in bytes.",
+			nil, nil,
+		),
+		storageFreeBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "system", "storage_free_bytes"),
+			"Free system storage (HDD) space in bytes.",
+			nil, nil,
+		),
+		storageUsedBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "system", "storage_used_bytes"),
+			"Used system storage (HDD) space in bytes.",
+			nil, nil,
+		),
+		routerOSInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "routeros", "info"),
+			"RouterOS version and release channel of the router.",
+			[]string{"version", "channel"}, nil,
+		),
+	}
+}
+
+func (c *systemCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.cpuLoadDesc
+	ch <- c.memoryUsageDesc
+	ch <- c.totalMemoryDesc
+	ch <- c.uptimeDesc
+	ch <- c.storageTotalBytesDesc
+	ch <- c.storageFreeBytesDesc
+	ch <- c.storageUsedBytesDesc
+	ch <- c.routerOSInfoDesc
+}
+
+func (c *systemCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	systemRes, err := client.GetSystemResources(ctx)
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.cpuLoadDesc, prometheus.GaugeValue, float64(systemRes.CPULoad))
+	ch <- prometheus.MustNewConstMetric(c.memoryUsageDesc, prometheus.GaugeValue, float64(systemRes.TotalMemory-systemRes.FreeMemory))
+	ch <- prometheus.MustNewConstMetric(c.totalMemoryDesc, prometheus.GaugeValue, float64(systemRes.TotalMemory))
+	ch <- prometheus.MustNewConstMetric(c.uptimeDesc, prometheus.GaugeValue, systemRes.Uptime.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.storageTotalBytesDesc, prometheus.GaugeValue, float64(systemRes.TotalHDDSpace))
+	ch <- prometheus.MustNewConstMetric(c.storageFreeBytesDesc, prometheus.GaugeValue, float64(systemRes.FreeHDDSpace))
+	ch <- prometheus.MustNewConstMetric(c.storageUsedBytesDesc, prometheus.GaugeValue, float64(systemRes.TotalHDDSpace-systemRes.FreeHDDSpace))
+
+	// The version comes from the capability probe, which is not essential to
+	// the system metrics, so mikrotik_routeros_info is left out if it fails.
+	caps, err := client.Capabilities(ctx)
+	if err != nil {
+		log.Printf("Warning: Could not detect RouterOS version of %s: %v", client.Target(), err)
+		return nil
+	}
+	ch <- prometheus.MustNewConstMetric(c.routerOSInfoDesc, prometheus.GaugeValue, 1, caps.Version, caps.Channel)
+	return nil
+}
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("wireless", false, newWirelessCollector)
+}
+
+type wirelessCollector struct {
+	interfaceInfoDesc           *prometheus.Desc
+	interfaceSignalStrengthDesc *prometheus.Desc
+	interfaceTxRateDesc         *prometheus.Desc
+	interfaceRxRateDesc         *prometheus.Desc
+	clientInfoDesc              *prometheus.Desc
+	clientSignalStrengthDesc    *prometheus.Desc
+	clientTxCCQDesc             *prometheus.Desc
+	activeClientsDesc           *prometheus.Desc
+}
+
+func newWirelessCollector(Options) SubCollector {
+	return &wirelessCollector{
+		interfaceInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wireless_interface", "info"),
+			"Wireless interface information.",
+			[]string{"name", "ssid", "frequency"},
+			nil,
+		),
+		interfaceSignalStrengthDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wireless_interface", "signal_strength_dbm"),
+			"Wireless interface signal strength in dBm (primarily for station mode).",
+			[]string{"name"},
+			nil,
+		),
+		interfaceTxRateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wireless_interface", "transmit_rate_bps"),
+			"Wireless interface transmit rate in bits per second.",
+			[]string{"name"},
+			nil,
+		),
+		interfaceRxRateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wireless_interface", "receive_rate_bps"),
+			"Wireless interface receive rate in bits per second.",
+			[]string{"name"},
+			nil,
+		),
+		clientInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wireless_client", "info"),
+			"Connected wireless client information (1 = connected).",
+			[]string{"interface", "mac_address", "uptime_text"},
+			nil,
+		),
+		clientSignalStrengthDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wireless_client", "signal_strength_dbm"),
+			"Connected wireless client signal strength in dBm.",
+			[]string{"interface", "mac_address"},
+			nil,
+		),
+		clientTxCCQDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wireless_client", "transmit_ccq_percent"),
+			"Connected wireless client transmit CCQ (Client Connection Quality) in percent.",
+			[]string{"interface", "mac_address"},
+			nil,
+		),
+		activeClientsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wireless_interface", "active_clients_count"),
+			"Number of active clients connected to a wireless interface (AP mode).",
+			[]string{"interface"},
+			nil,
+		),
+	}
+}
+
+func (c *wirelessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.interfaceInfoDesc
+	ch <- c.interfaceSignalStrengthDesc
+	ch <- c.interfaceTxRateDesc
+	ch <- c.interfaceRxRateDesc
+	ch <- c.clientInfoDesc
+	ch <- c.clientSignalStrengthDesc
+	ch <- c.clientTxCCQDesc
+	ch <- c.activeClientsDesc
+}
+
+func (c *wirelessCollector) Collect(ctx context.Context, client Client, ch chan<- prometheus.Metric) error {
+	wirelessInterfaces, ifErr := client.FetchWirelessInterfaces(ctx)
+	if ifErr == nil {
+		for _, iface := range wirelessInterfaces {
+			ch <- prometheus.MustNewConstMetric(c.interfaceInfoDesc, prometheus.GaugeValue, 1,
+				iface.Name, iface.SSID, strconv.Itoa(iface.Frequency),
+			)
+			if iface.SignalStrength != 0 {
+				ch <- prometheus.MustNewConstMetric(c.interfaceSignalStrengthDesc, prometheus.GaugeValue, float64(iface.SignalStrength), iface.Name)
+			}
+			if iface.TxRate > 0 {
+				ch <- prometheus.MustNewConstMetric(c.interfaceTxRateDesc, prometheus.GaugeValue, iface.TxRate, iface.Name)
+			}
+			if iface.RxRate > 0 {
+				ch <- prometheus.MustNewConstMetric(c.interfaceRxRateDesc, prometheus.GaugeValue, iface.RxRate, iface.Name)
+			}
+		}
+	}
+
+	wirelessClients, clientErr := client.FetchWirelessClients(ctx)
+	if clientErr == nil {
+		clientCounts := make(map[string]int)
+		for _, wc := range wirelessClients {
+			clientCounts[wc.Interface]++
+
+			ch <- prometheus.MustNewConstMetric(c.clientInfoDesc, prometheus.GaugeValue, 1,
+				wc.Interface, wc.MacAddress, wc.Uptime,
+			)
+			if wc.SignalStrength != 0 {
+				ch <- prometheus.MustNewConstMetric(c.clientSignalStrengthDesc, prometheus.GaugeValue, float64(wc.SignalStrength), wc.Interface, wc.MacAddress)
+			}
+			if wc.TxCCQ != 0 {
+				ch <- prometheus.MustNewConstMetric(c.clientTxCCQDesc, prometheus.GaugeValue, float64(wc.TxCCQ), wc.Interface, wc.MacAddress)
+			}
+		}
+
+		for ifaceName, count := range clientCounts {
+			ch <- prometheus.MustNewConstMetric(c.activeClientsDesc, prometheus.GaugeValue, float64(count), ifaceName)
+		}
+	}
+
+	return errors.Join(ifErr, clientErr)
+}
@@ -0,0 +1,251 @@
+package mikrotik
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAddressListNamesTTL is how long the discovered address list names of
+// a router are reused before they are listed again.
+const DefaultAddressListNamesTTL = 15 * time.Minute
+
+// maxLiteralAddressLists bounds the number of names an include expression may
+// spell out to be counted without discovering the lists first.
+const maxLiteralAddressLists = 64
+
+// AddressListCount is the number of entries of a firewall address list.
+type AddressListCount struct {
+	// Family is ipv4 for /ip/firewall/address-list and ipv6 for
+	// /ipv6/firewall/address-list.
+	Family  string
+	List    string
+	Static  uint64
+	Dynamic uint64
+}
+
+// addressListMenus are the address list menus by address family.
+var addressListMenus = []struct {
+	family string
+	menu   string
+}{
+	{"ipv4", "/ip/firewall/address-list/print"},
+	{"ipv6", "/ipv6/firewall/address-list/print"},
+}
+
+type addressListEntry struct {
+	mu      sync.Mutex
+	names   []string
+	expires time.Time
+}
+
+// addressListCache holds the address list names of every target. Finding the
+// names requires listing the list attribute of every entry, so they are only
+// refreshed after the TTL.
+type addressListCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*addressListEntry
+}
+
+var addressListNames = &addressListCache{
+	ttl:     DefaultAddressListNamesTTL,
+	entries: make(map[string]*addressListEntry),
+}
+
+// SetAddressListNamesTTL sets how long discovered address list names are
+// reused. Zero or less lists the names on every scrape.
+func SetAddressListNamesTTL(ttl time.Duration) {
+	addressListNames.mu.Lock()
+	defer addressListNames.mu.Unlock()
+	addressListNames.ttl = ttl
+}
+
+func (lc *addressListCache) entry(key string) (*addressListEntry, time.Duration) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	e, ok := lc.entries[key]
+	if !ok {
+		e = &addressListEntry{}
+		lc.entries[key] = e
+	}
+	return e, lc.ttl
+}
+
+// GetAddressListCounts counts the static and dynamic entries of the IPv4 and
+// IPv6 address lists whose name matches include, or of all lists if include
+// is nil. The entries are counted with count-only queries per list.
+//
+// If include only matches a fixed set of names, such as ^(blocklist|ddos)$,
+// those lists are counted directly, and lists without entries are reported
+// with zero entries in both families.
+// Otherwise the list names are discovered by printing only the list attribute
+// of the entries, which is cached for the address list names TTL, so lists
+// created in between are picked up late.
+func (c *Client) GetAddressListCounts(ctx context.Context, include *regexp.Regexp) ([]AddressListCount, error) {
+	literal, isLiteral := literalNames(include)
+
+	var counts []AddressListCount
+	for _, family := range addressListMenus {
+		names := literal
+		if !isLiteral {
+			var err error
+			names, err = c.getAddressListNames(ctx, family.menu)
+			if err != nil {
+				if strings.Contains(err.Error(), "no such command") {
+					log.Printf("No %s menu on %s. Skipping %s address list metrics.", family.menu, c.Address, family.family)
+					continue
+				}
+				return nil, fmt.Errorf("failed to get %s address lists: %w", family.family, err)
+			}
+		}
+
+		for _, name := range names {
+			if include != nil && !include.MatchString(name) {
+				continue
+			}
+			total, err := c.count(ctx, family.menu, "?list="+name)
+			if err != nil {
+				if isLiteral && strings.Contains(err.Error(), "no such command") {
+					log.Printf("No %s menu on %s. Skipping %s address list metrics.", family.menu, c.Address, family.family)
+					break
+				}
+				return nil, fmt.Errorf("failed to count %s address list %s: %w", family.family, name, err)
+			}
+			dynamic := uint64(0)
+			if total > 0 {
+				dynamic, err = c.count(ctx, family.menu, "?list="+name, "?dynamic=true")
+				if err != nil {
+					return nil, fmt.Errorf("failed to count dynamic entries of %s address list %s: %w", family.family, name, err)
+				}
+			}
+			counts = append(counts, AddressListCount{
+				Family:  family.family,
+				List:    name,
+				Static:  total - min(dynamic, total),
+				Dynamic: dynamic,
+			})
+		}
+	}
+	return counts, nil
+}
+
+// getAddressListNames returns the sorted names of the address lists in menu.
+// Recording clients always list the names, so that the listing is part of the
+// fixture.
+func (c *Client) getAddressListNames(ctx context.Context, menu string) ([]string, error) {
+	e, ttl := addressListNames.entry(c.Backend + "|" + c.Address + "|" + menu)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.names != nil && time.Now().Before(e.expires) && c.Recorder == nil {
+		return e.names, nil
+	}
+
+	reply, err := c.Run(ctx, menu, "without-paging", "=.proplist=list")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, re := range reply.Re {
+		if name := re.Map["list"]; name != "" {
+			seen[name] = true
+		}
+	}
+	names := slices.Sorted(maps.Keys(seen))
+	if names == nil {
+		names = []string{}
+	}
+	e.names = names
+	e.expires = time.Now().Add(ttl)
+	return names, nil
+}
+
+// literalNames returns the names matched by re if it is anchored at both ends
+// and only spells out a small set of names, e.g. ^(blocklist|ddos)$.
+func literalNames(re *regexp.Regexp) ([]string, bool) {
+	if re == nil {
+		return nil, false
+	}
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return nil, false
+	}
+	parsed = parsed.Simplify()
+	if parsed.Op != syntax.OpConcat || len(parsed.Sub) < 2 ||
+		parsed.Sub[0].Op != syntax.OpBeginText || parsed.Sub[len(parsed.Sub)-1].Op != syntax.OpEndText {
+		return nil, false
+	}
+	names := []string{""}
+	for _, sub := range parsed.Sub[1 : len(parsed.Sub)-1] {
+		var ok bool
+		if names, ok = expandLiteral(names, sub); !ok {
+			return nil, false
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names), true
+}
+
+// expandLiteral appends every string matched by re to each of prefixes.
+func expandLiteral(prefixes []string, re *syntax.Regexp) ([]string, bool) {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		return prefixes, true
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return nil, false
+		}
+		out := make([]string, len(prefixes))
+		for i, p := range prefixes {
+			out[i] = p + string(re.Rune)
+		}
+		return out, true
+	case syntax.OpCharClass:
+		// Alternatives with a common prefix are parsed as a class, e.g.
+		// bogons-v4|bogons-v6 as bogons-v[46].
+		var out []string
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				if len(out)+len(prefixes) > maxLiteralAddressLists {
+					return nil, false
+				}
+				for _, p := range prefixes {
+					out = append(out, p+string(r))
+				}
+			}
+		}
+		return out, true
+	case syntax.OpCapture:
+		return expandLiteral(prefixes, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			var ok bool
+			if prefixes, ok = expandLiteral(prefixes, sub); !ok {
+				return nil, false
+			}
+		}
+		return prefixes, true
+	case syntax.OpAlternate:
+		var out []string
+		for _, sub := range re.Sub {
+			expanded, ok := expandLiteral(prefixes, sub)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, expanded...)
+			if len(out) > maxLiteralAddressLists {
+				return nil, false
+			}
+		}
+		return out, true
+	}
+	return nil, false
+}
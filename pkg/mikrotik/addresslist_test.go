@@ -0,0 +1,83 @@
+package mikrotik
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestLiteralNames(t *testing.T) {
+	for expr, want := range map[string][]string{
+		`^(blocklist|ddos)$`:        {"blocklist", "ddos"},
+		`^blocklist$`:               {"blocklist"},
+		`^(?:ddos|block(list|ed))$`: {"blocked", "blocklist", "ddos"},
+		`^(bogons-v4|bogons-v6)$`:   {"bogons-v4", "bogons-v6"},
+		`^ddos[1-3]$`:               {"ddos1", "ddos2", "ddos3"},
+	} {
+		got, ok := literalNames(regexp.MustCompile(expr))
+		if !ok || !slices.Equal(got, want) {
+			t.Errorf("literalNames(%q) = %q, %v; want %q", expr, got, ok, want)
+		}
+	}
+
+	for _, expr := range []string{`blocklist`, `^blocklist`, `^(blocklist|ddos)`, `^block.*$`, `^(?i)ddos$`, `^ddos[0-9]+$`, `^a?$`} {
+		if got, ok := literalNames(regexp.MustCompile(expr)); ok {
+			t.Errorf("literalNames(%q) = %q; want no literal names", expr, got)
+		}
+	}
+	if _, ok := literalNames(nil); ok {
+		t.Error("literalNames(nil) returned literal names")
+	}
+}
+
+func TestGetAddressListCounts(t *testing.T) {
+	count := func(menu, ret string, queries ...string) FixtureCommand {
+		return FixtureCommand{Command: menu, Args: append([]string{"=count-only="}, queries...), Done: map[string]string{"ret": ret}}
+	}
+	const v4, v6 = "/ip/firewall/address-list/print", "/ipv6/firewall/address-list/print"
+	client := NewClient("addresslist-test", "", "", DefaultTimeout)
+	client.Backend = BackendReplay
+	client.Fixture = &Fixture{Commands: []FixtureCommand{
+		{Command: v4, Args: []string{"=.proplist=list"}, Replies: []map[string]string{
+			{"list": "blocklist"}, {"list": "blocklist"}, {"list": "bogons"},
+		}},
+		count(v4, "5", "?list=blocklist"),
+		count(v4, "2", "?list=blocklist", "?dynamic=true"),
+		count(v4, "1", "?list=bogons"),
+		count(v4, "0", "?list=bogons", "?dynamic=true"),
+		count(v4, "0", "?list=ddos"),
+		// The ipv6 package is not installed.
+	}}
+
+	for _, tc := range []struct {
+		include string
+		want    []AddressListCount
+	}{
+		{"", []AddressListCount{
+			{Family: "ipv4", List: "blocklist", Static: 3, Dynamic: 2},
+			{Family: "ipv4", List: "bogons", Static: 1},
+		}},
+		// Discovered names are filtered by the expression.
+		{"^block", []AddressListCount{
+			{Family: "ipv4", List: "blocklist", Static: 3, Dynamic: 2},
+		}},
+		// Literal names are counted without discovery, also without entries.
+		{"^(blocklist|ddos)$", []AddressListCount{
+			{Family: "ipv4", List: "blocklist", Static: 3, Dynamic: 2},
+			{Family: "ipv4", List: "ddos"},
+		}},
+	} {
+		var include *regexp.Regexp
+		if tc.include != "" {
+			include = regexp.MustCompile(tc.include)
+		}
+		got, err := client.GetAddressListCounts(context.Background(), include)
+		if err != nil {
+			t.Fatalf("include %q: %v", tc.include, err)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("include %q: got %+v, want %+v", tc.include, got, tc.want)
+		}
+	}
+}
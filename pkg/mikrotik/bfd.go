@@ -0,0 +1,110 @@
+package mikrotik
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// BFDSession is a BFD session from /routing/bfd/session (RouterOS 7) or
+// /routing/bfd/neighbor (RouterOS 6).
+type BFDSession struct {
+	RemoteAddress string
+	LocalAddress  string
+	Interface     string
+	// VRF is the routing table of the session on RouterOS 7 and empty on
+	// RouterOS 6.
+	VRF               string
+	State             string
+	StateChanges      uint64
+	Uptime            time.Duration
+	DesiredTxInterval time.Duration
+	RequiredMinRx     time.Duration
+	RemoteMinRx       time.Duration
+	ActualTxInterval  time.Duration
+	Multiplier        uint64
+	PacketsRx         uint64
+	PacketsTx         uint64
+}
+
+// GetBFDSessions fetches the BFD sessions from the menu of the RouterOS version.
+func (c *Client) GetBFDSessions(ctx context.Context) ([]BFDSession, error) {
+	caps := c.capabilities(ctx)
+	if !caps.HasBFD() {
+		log.Printf("Routing package is disabled on %s. Skipping BFD metrics.", c.Address)
+		return []BFDSession{}, nil
+	}
+
+	// Without a detected version, try the RouterOS 7 menu first.
+	menus := []string{"/routing/bfd/session/print", "/routing/bfd/neighbor/print"}
+	switch {
+	case caps.IsV7():
+		menus = menus[:1]
+	case caps.Major != 0:
+		menus = menus[1:]
+	}
+
+	var err error
+	for _, menu := range menus {
+		var sessions []BFDSession
+		sessions, err = c.getBFDSessions(ctx, menu)
+		if err == nil {
+			return sessions, nil
+		}
+		if !strings.Contains(err.Error(), "no such command") {
+			return nil, fmt.Errorf("failed to get BFD sessions: %w", err)
+		}
+	}
+	log.Printf("BFD feature might be disabled on %s. Skipping BFD metrics.", c.Address)
+	return []BFDSession{}, nil
+}
+
+func (c *Client) getBFDSessions(ctx context.Context, menu string) ([]BFDSession, error) {
+	reply, err := c.Run(ctx, menu, "without-paging")
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]BFDSession, 0, len(reply.Re))
+	for _, re := range reply.Re {
+		session := BFDSession{
+			RemoteAddress: re.Map["remote-address"],
+			LocalAddress:  re.Map["local-address"],
+			Interface:     re.Map["interface"],
+			VRF:           re.Map["vrf"],
+			State:         re.Map["state"],
+			StateChanges:  parseUint(re.Map["state-changes"]),
+			Multiplier:    parseUint(re.Map["multiplier"]),
+			PacketsRx:     parseUint(re.Map["packets-rx"]),
+			PacketsTx:     parseUint(re.Map["packets-tx"]),
+		}
+		// RouterOS 6 neighbors are configured by their address.
+		if session.RemoteAddress == "" {
+			session.RemoteAddress = re.Map["address"]
+		}
+		if session.RemoteAddress == "" {
+			log.Printf("Warning: Skipping BFD session without remote address: %v", re.Map)
+			continue
+		}
+
+		for field, d := range map[string]*time.Duration{
+			"uptime":              &session.Uptime,
+			"desired-tx-interval": &session.DesiredTxInterval,
+			"required-min-rx":     &session.RequiredMinRx,
+			"remote-min-rx":       &session.RemoteMinRx,
+			"actual-tx-interval":  &session.ActualTxInterval,
+		} {
+			value := re.Map[field]
+			if value == "" {
+				continue
+			}
+			if *d, err = parseMikrotikDuration(value); err != nil {
+				log.Printf("Warning: Could not parse BFD %s '%s' for session '%s': %v", field, value, session.RemoteAddress, err)
+			}
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
@@ -1,26 +1,34 @@
 package mikrotik
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/go-routeros/routeros/v3/proto"
 )
 
-func (c *Client) GetBGPPeerStats() ([]BGPPeerStat, error) {
+func (c *Client) GetBGPPeerStats(ctx context.Context) ([]BGPPeerStat, error) {
+	if !c.capabilities(ctx).HasBGPPeerMenu() {
+		log.Printf("No /routing/bgp/peer menu on %s. Skipping BGP peer metrics.", c.Address)
+		return []BGPPeerStat{}, nil
+	}
+
 	cmd := []string{
 		"/routing/bgp/peer/print",
 		"without-paging",
 	}
-	reply, err := c.Run(cmd...)
+	reply, err := c.Run(ctx, cmd...)
 
 	if err != nil && (strings.Contains(err.Error(), "no such command") || strings.Contains(err.Error(), "disabled")) {
 		cmd = []string{
 			"/ip/bgp/peer/print",
 			"without-paging",
 		}
-		reply, err = c.Run(cmd...)
+		reply, err = c.Run(ctx, cmd...)
 	}
 	if err != nil {
 		if strings.Contains(err.Error(), "no such command") || strings.Contains(err.Error(), "disabled") {
@@ -117,24 +125,225 @@ func (c *Client) GetBGPPeerStats() ([]BGPPeerStat, error) {
 		}
 
 		stat := BGPPeerStat{
-			Name:          name,
-			Instance:      re.Map["instance"],
-			RemoteAddress: re.Map["remote-address"],
-			RemoteAS:      re.Map["remote-as"],
-			LocalAddress:  re.Map["local-address"],
-			LocalRole:     re.Map["local-role"],
-			RemoteRole:    re.Map["remote-role"],
-			State:         state,
-			Uptime:        uptime,
-			PrefixCount:   prefixCount,
-			UpdatesSent:   updatesSent,
-			UpdatesRecv:   updatesRecv,
-			WithdrawsSent: withdrawsSent,
-			WithdrawsRecv: withdrawsRecv,
-			Disabled:      disabled,
+			Name:            name,
+			Instance:        re.Map["instance"],
+			RemoteAddress:   re.Map["remote-address"],
+			RemoteAS:        re.Map["remote-as"],
+			LocalAddress:    re.Map["local-address"],
+			LocalRole:       re.Map["local-role"],
+			RemoteRole:      re.Map["remote-role"],
+			AddressFamilies: re.Map["address-families"],
+			State:           state,
+			Uptime:          uptime,
+			PrefixCount:     prefixCount,
+			UpdatesSent:     updatesSent,
+			UpdatesRecv:     updatesRecv,
+			WithdrawsSent:   withdrawsSent,
+			WithdrawsRecv:   withdrawsRecv,
+			Disabled:        disabled,
 		}
 		stats = append(stats, stat)
 	}
 
 	return stats, nil
 }
+
+// GetBGPSessionStats returns the BGP sessions and connections of RouterOS 7.
+// Each session is correlated with its connection, which holds the configured
+// name and template; the connections include those without a session. Both
+// are built from a single print of /routing/bgp/connection.
+func (c *Client) GetBGPSessionStats(ctx context.Context) ([]BGPSessionStat, []BGPConnection, error) {
+	if !c.capabilities(ctx).HasBGPSessionMenu() {
+		return []BGPSessionStat{}, []BGPConnection{}, nil
+	}
+
+	reply, err := c.Run(ctx, "/routing/bgp/session/print", "without-paging")
+	if err != nil {
+		if strings.Contains(err.Error(), "no such command") {
+			log.Printf("No /routing/bgp/session menu on %s. Skipping BGP session metrics.", c.Address)
+			return []BGPSessionStat{}, []BGPConnection{}, nil
+		}
+		return nil, nil, fmt.Errorf("failed to get BGP sessions: %w", err)
+	}
+
+	connReply, err := c.Run(ctx, "/routing/bgp/connection/print", "without-paging")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get BGP connections: %w", err)
+	}
+	connections := make([]BGPConnection, 0, len(connReply.Re))
+	byName := make(map[string]*proto.Sentence, len(connReply.Re))
+	for _, re := range connReply.Re {
+		name := re.Map["name"]
+		if name == "" {
+			continue
+		}
+		byName[name] = re
+		connections = append(connections, BGPConnection{
+			Name:     name,
+			Disabled: parseBool(re.Map["disabled"]),
+			Connect:  re.Map["connect"] == "" || parseBool(re.Map["connect"]),
+		})
+	}
+
+	stats := make([]BGPSessionStat, 0, len(reply.Re))
+	for _, re := range reply.Re {
+		name := re.Map["name"]
+		if name == "" {
+			log.Printf("Warning: Skipping BGP session with empty name: %v", re.Map)
+			continue
+		}
+
+		stat := BGPSessionStat{
+			Name:             name,
+			RemoteAddress:    re.Map["remote.address"],
+			RemoteAS:         re.Map["remote.as"],
+			RemoteID:         re.Map["remote.id"],
+			LocalAddress:     re.Map["local.address"],
+			LocalAS:          re.Map["local.as"],
+			LocalID:          re.Map["local.id"],
+			LocalRole:        re.Map["local.role"],
+			AddressFamilies:  re.Map["remote.afi"],
+			Established:      parseBool(re.Map["established"]),
+			PrefixCount:      parseUint(re.Map["prefix-count"]),
+			MessagesSent:     parseUint(re.Map["local.messages"]),
+			MessagesReceived: parseUint(re.Map["remote.messages"]),
+			BytesSent:        parseUint(re.Map["local.bytes"]),
+			BytesReceived:    parseUint(re.Map["remote.bytes"]),
+		}
+		stat.Uptime = parseSessionDuration(re.Map["uptime"], "uptime", name)
+		stat.HoldTime = parseSessionDuration(re.Map["hold-time"], "hold-time", name)
+		stat.KeepaliveTime = parseSessionDuration(re.Map["keepalive-time"], "keepalive-time", name)
+
+		if conn, ok := matchBGPConnection(byName, stat); ok {
+			stat.Connection = conn.Map["name"]
+			stat.Template = conn.Map["templates"]
+			if stat.Template == "" {
+				stat.Template = conn.Map["template"]
+			}
+			if stat.LocalRole == "" {
+				stat.LocalRole = conn.Map["local.role"]
+			}
+			if stat.AddressFamilies == "" {
+				stat.AddressFamilies = conn.Map["address-families"]
+			}
+		}
+		if stat.Established {
+			stat.Prefixes, err = c.getBGPSessionPrefixes(ctx, stat)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, nil, err
+				}
+				log.Printf("Warning: Could not count prefixes of BGP session '%s' on %s: %v", name, c.Address, err)
+			}
+		}
+		stats = append(stats, stat)
+	}
+	return stats, connections, nil
+}
+
+// getBGPSessionPrefixes counts the routes received in a session per address
+// family. RouterOS 7 marks the routes of a session with belongs-to
+// "bgp-IP-<remote address>" and keeps routes rejected by the input filter in
+// the table with the filtered flag.
+func (c *Client) getBGPSessionPrefixes(ctx context.Context, session BGPSessionStat) (map[string]BGPPrefixCounts, error) {
+	if session.AddressFamilies == "" || session.RemoteAddress == "" {
+		return nil, nil
+	}
+	belongsTo := "?belongs-to=bgp-IP-" + session.RemoteAddress
+
+	prefixes := make(map[string]BGPPrefixCounts)
+	for _, afi := range strings.Split(session.AddressFamilies, ",") {
+		received, err := c.count(ctx, "/routing/route/print", belongsTo, "?afi="+afi)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s routes: %w", afi, err)
+		}
+		filtered, err := c.count(ctx, "/routing/route/print", belongsTo, "?afi="+afi, "?filtered=true")
+		if err != nil {
+			return nil, fmt.Errorf("failed to count filtered %s routes: %w", afi, err)
+		}
+		prefixes[AddressFamilyLabel(afi)] = BGPPrefixCounts{
+			Received: received,
+			Accepted: received - min(filtered, received),
+			Filtered: filtered,
+		}
+	}
+	return prefixes, nil
+}
+
+// GetBGPAdvertisedPrefixes counts the prefixes advertised to each BGP peer or
+// session by address family, keyed by the peer or session name. It lists every
+// advertised prefix, which is expensive on routers sending full tables.
+func (c *Client) GetBGPAdvertisedPrefixes(ctx context.Context) (map[string]map[string]uint64, error) {
+	reply, err := c.Run(ctx, "/routing/bgp/advertisements/print", "without-paging", "=.proplist=peer,prefix,dst,afi")
+	if err != nil {
+		if strings.Contains(err.Error(), "no such command") {
+			return map[string]map[string]uint64{}, nil
+		}
+		return nil, fmt.Errorf("failed to get BGP advertisements: %w", err)
+	}
+
+	advertised := make(map[string]map[string]uint64)
+	for _, re := range reply.Re {
+		peer := re.Map["peer"]
+		if peer == "" {
+			continue
+		}
+		afi := re.Map["afi"]
+		if afi == "" {
+			// RouterOS 6 only reports the prefix.
+			afi = "ip"
+			if strings.Contains(re.Map["prefix"]+re.Map["dst"], ":") {
+				afi = "ipv6"
+			}
+		}
+		if advertised[peer] == nil {
+			advertised[peer] = make(map[string]uint64)
+		}
+		advertised[peer][AddressFamilyLabel(afi)]++
+	}
+	return advertised, nil
+}
+
+// AddressFamilyLabel returns the label value of a RouterOS address family, e.g.
+// "ipv4" for "ip". Other families such as ipv6, vpnv4 and l2vpn keep their name.
+func AddressFamilyLabel(afi string) string {
+	afi = strings.TrimSpace(afi)
+	if afi == "ip" {
+		return "ipv4"
+	}
+	return afi
+}
+
+// matchBGPConnection finds the connection of a session. RouterOS names
+// sessions after their connection with a "-<n>" suffix; sessions of
+// connections listening for any remote are matched by remote address.
+func matchBGPConnection(connections map[string]*proto.Sentence, session BGPSessionStat) (*proto.Sentence, bool) {
+	if conn, ok := connections[session.Name]; ok {
+		return conn, true
+	}
+	if i := strings.LastIndex(session.Name, "-"); i > 0 {
+		if _, err := strconv.Atoi(session.Name[i+1:]); err == nil {
+			if conn, ok := connections[session.Name[:i]]; ok {
+				return conn, true
+			}
+		}
+	}
+	for _, conn := range connections {
+		remote, _, _ := strings.Cut(conn.Map["remote.address"], "/")
+		if remote != "" && remote == session.RemoteAddress {
+			return conn, true
+		}
+	}
+	return nil, false
+}
+
+func parseSessionDuration(value, field, name string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	d, err := parseMikrotikDuration(value)
+	if err != nil {
+		log.Printf("Warning: Could not parse BGP session %s '%s' for session '%s': %v", field, value, name, err)
+	}
+	return d
+}
@@ -0,0 +1,268 @@
+package mikrotik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCapabilitiesTTL is how long the detected capabilities of a router are
+// reused before the router is probed again.
+const DefaultCapabilitiesTTL = time.Hour
+
+// capabilitiesRetryInterval is how long a failed probe is remembered, so that
+// a router that cannot be probed, e.g. for lack of permissions, is not probed
+// again by every collector of every scrape.
+const capabilitiesRetryInterval = time.Minute
+
+// Wireless menus of the different RouterOS wireless packages.
+const (
+	WirelessMenuLegacy    = "/interface/wireless"
+	WirelessMenuWifiWave2 = "/interface/wifiwave2"
+	WirelessMenuWifi      = "/interface/wifi"
+)
+
+// Capabilities describes the RouterOS version of a router and the menus
+// available on it, so that collectors can run the matching commands directly.
+// A zero Major version means the version could not be detected.
+type Capabilities struct {
+	Version string
+	Channel string
+	Major   int
+	Minor   int
+
+	// Packages lists the installed packages and whether they are enabled.
+	// It is nil if the package list could not be read.
+	Packages map[string]bool
+}
+
+// IsV7 reports whether the router runs RouterOS 7 or later.
+func (c *Capabilities) IsV7() bool {
+	return c.Major >= 7
+}
+
+// packageEnabled reports whether the named package is installed and enabled.
+// ok is false if the package list is unknown.
+func (c *Capabilities) packageEnabled(name string) (enabled, ok bool) {
+	if c.Packages == nil {
+		return false, false
+	}
+	return c.Packages[name], true
+}
+
+// HasBGPPeerMenu reports whether /routing/bgp/peer of RouterOS 6 is
+// available. It is assumed to be if the version is unknown.
+func (c *Capabilities) HasBGPPeerMenu() bool {
+	if c.Major == 0 {
+		return true
+	}
+	if c.IsV7() {
+		return false
+	}
+	enabled, ok := c.packageEnabled("routing")
+	return enabled || !ok
+}
+
+// HasOSPF reports whether the OSPF menus are available.
+func (c *Capabilities) HasOSPF() bool {
+	return c.hasRouting()
+}
+
+// HasBFD reports whether the BFD menus are available.
+func (c *Capabilities) HasBFD() bool {
+	return c.hasRouting()
+}
+
+// hasRouting reports whether the routing protocols are available. RouterOS 6
+// needs the routing package; later versions always include them.
+func (c *Capabilities) hasRouting() bool {
+	if c.Major == 0 || c.IsV7() {
+		return true
+	}
+	enabled, ok := c.packageEnabled("routing")
+	return enabled || !ok
+}
+
+// HasBGPSessionMenu reports whether /routing/bgp/session of RouterOS 7 is available.
+func (c *Capabilities) HasBGPSessionMenu() bool {
+	return c.IsV7()
+}
+
+// HasPPP reports whether the PPP menus are available. The ppp package only
+// exists up to RouterOS 6; later versions always include PPP.
+func (c *Capabilities) HasPPP() bool {
+	if c.Major == 0 || c.IsV7() {
+		return true
+	}
+	enabled, ok := c.packageEnabled("ppp")
+	return enabled || !ok
+}
+
+// HasDHCP reports whether the DHCP server menus are available. The dhcp
+// package only exists up to RouterOS 6; later versions always include DHCP.
+func (c *Capabilities) HasDHCP() bool {
+	if c.Major == 0 || c.IsV7() {
+		return true
+	}
+	enabled, ok := c.packageEnabled("dhcp")
+	return enabled || !ok
+}
+
+// WirelessMenu returns the menu of the enabled wireless package, or "" if the
+// router has none. Without a package list the legacy menu is assumed.
+func (c *Capabilities) WirelessMenu() string {
+	if c.Packages == nil {
+		return WirelessMenuLegacy
+	}
+	switch {
+	case c.Packages["wifi-qcom"] || c.Packages["wifi-qcom-ac"]:
+		return WirelessMenuWifi
+	case c.Packages["wifiwave2"]:
+		return WirelessMenuWifiWave2
+	}
+	// RouterOS 6 also had driver variants such as wireless-cm2 and wireless-rep.
+	for name, enabled := range c.Packages {
+		if enabled && strings.HasPrefix(name, "wireless") {
+			return WirelessMenuLegacy
+		}
+	}
+	return ""
+}
+
+// HealthList reports whether /system/health/print returns one item per
+// sensor, as RouterOS 7 does, instead of a single item with all values.
+func (c *Capabilities) HealthList() bool {
+	return c.IsV7()
+}
+
+// parseVersion splits a version as reported by /system/resource, e.g.
+// "7.12.1 (stable)", into the version, the release channel and the major and
+// minor numbers.
+func parseVersion(s string) (version, channel string, major, minor int) {
+	version, channel, _ = strings.Cut(strings.TrimSpace(s), " ")
+	channel = strings.Trim(channel, "()")
+
+	parts := strings.SplitN(version, ".", 3)
+	major, _ = strconv.Atoi(parts[0])
+	if len(parts) > 1 {
+		// Pre-releases are named like 7.13beta2.
+		digits := strings.IndexFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' })
+		if digits < 0 {
+			digits = len(parts[1])
+		}
+		minor, _ = strconv.Atoi(parts[1][:digits])
+	}
+	return version, channel, major, minor
+}
+
+type capabilityEntry struct {
+	mu      sync.Mutex
+	caps    *Capabilities
+	err     error
+	expires time.Time
+}
+
+// capabilityCache holds the capabilities of every target the exporter has
+// scraped, shared between clients.
+type capabilityCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*capabilityEntry
+}
+
+var targetCapabilities = &capabilityCache{
+	ttl:     DefaultCapabilitiesTTL,
+	entries: make(map[string]*capabilityEntry),
+}
+
+// SetCapabilitiesTTL sets how long detected capabilities are reused. Zero or
+// less probes the router on every scrape.
+func SetCapabilitiesTTL(ttl time.Duration) {
+	targetCapabilities.mu.Lock()
+	defer targetCapabilities.mu.Unlock()
+	targetCapabilities.ttl = ttl
+}
+
+func (cc *capabilityCache) entry(key string) (*capabilityEntry, time.Duration) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	e, ok := cc.entries[key]
+	if !ok {
+		e = &capabilityEntry{}
+		cc.entries[key] = e
+	}
+	return e, cc.ttl
+}
+
+// Capabilities returns the capabilities of the router. They are probed on
+// first use and cached per target; concurrent callers wait for a single probe.
+// A failed probe is cached as well, for at most capabilitiesRetryInterval,
+// unless it failed because ctx ended. Recording clients always probe, so that
+// the probe is part of the fixture.
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	e, ttl := targetCapabilities.entry(c.Backend + "|" + c.Address)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if (e.caps != nil || e.err != nil) && time.Now().Before(e.expires) && c.Recorder == nil {
+		return e.caps, e.err
+	}
+
+	caps, err := c.probeCapabilities(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			e.caps, e.err = nil, err
+			e.expires = time.Now().Add(min(ttl, capabilitiesRetryInterval))
+		}
+		return nil, err
+	}
+	e.caps, e.err = caps, nil
+	e.expires = time.Now().Add(ttl)
+	return caps, nil
+}
+
+// capabilities returns the capabilities of the router, or unknown
+// capabilities if they cannot be detected. Callers then fall back to trying
+// commands and handling "no such command" errors.
+func (c *Client) capabilities(ctx context.Context) *Capabilities {
+	caps, err := c.Capabilities(ctx)
+	if err != nil {
+		log.Printf("Warning: Could not detect capabilities of %s: %v", c.Address, err)
+		return &Capabilities{}
+	}
+	return caps
+}
+
+func (c *Client) probeCapabilities(ctx context.Context) (*Capabilities, error) {
+	reply, err := c.Run(ctx, "/system/resource/print", "=.proplist=version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get RouterOS version: %w", err)
+	}
+	if len(reply.Re) == 0 || reply.Re[0].Map["version"] == "" {
+		return nil, errors.New("no RouterOS version received")
+	}
+
+	caps := &Capabilities{}
+	caps.Version, caps.Channel, caps.Major, caps.Minor = parseVersion(reply.Re[0].Map["version"])
+
+	packages, err := c.Run(ctx, "/system/package/print", "without-paging", "=.proplist=name,disabled")
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to get packages: %w", ctx.Err())
+		}
+		log.Printf("Warning: Could not list packages on %s, assuming all menus are available: %v", c.Address, err)
+	} else {
+		caps.Packages = make(map[string]bool, len(packages.Re))
+		for _, re := range packages.Re {
+			caps.Packages[re.Map["name"]] = !parseBool(re.Map["disabled"])
+		}
+	}
+
+	log.Printf("Detected RouterOS %s (%s) on %s, wireless menu: %q", caps.Version, caps.Channel, c.Address, caps.WirelessMenu())
+	return caps, nil
+}
@@ -0,0 +1,32 @@
+package mikrotik_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/taihen/ros-exporter/pkg/mikrotik"
+	"github.com/taihen/ros-exporter/pkg/mikrotik/fakeapi"
+)
+
+func TestCapabilitiesCachesFailure(t *testing.T) {
+	srv, err := fakeapi.NewServer(&mikrotik.Fixture{Commands: []mikrotik.FixtureCommand{
+		{Command: "/system/resource/print", Trap: "not enough permissions (9)"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	client := mikrotik.NewClient(srv.Addr(), "prometheus", "secret", time.Second)
+	defer client.Close()
+	ctx := context.Background()
+	for range 3 {
+		if _, err := client.Capabilities(ctx); err == nil {
+			t.Fatal("Capabilities() of a router denying /system/resource succeeded")
+		}
+	}
+	if got := srv.Commands()["/system/resource/print"]; got != 1 {
+		t.Errorf("router was probed %d times, want 1", got)
+	}
+}
@@ -1,26 +1,74 @@
 package mikrotik
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log"
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/proto"
 )
 
 const defaultMikrotikAPIPort = "8728"
+const defaultMikrotikAPISSLPort = "8729"
 const DefaultTimeout = 10 * time.Second
 
+// DefaultMaxConcurrency is the default number of API commands a client keeps
+// in flight on a single connection.
+const DefaultMaxConcurrency = 4
+
+// Backends a client can use to talk to a router.
+const (
+	// BackendAPI is the binary RouterOS API (api and api-ssl services).
+	BackendAPI = "api"
+	// BackendREST is the REST API of RouterOS v7 served by the www and
+	// www-ssl services.
+	BackendREST = "rest"
+	// BackendReplay answers commands from the Fixture of the client instead
+	// of a router.
+	BackendReplay = "replay"
+)
+
+// ValidBackend reports whether name selects a known backend. The empty name
+// selects the binary API.
+func ValidBackend(name string) bool {
+	return name == "" || name == BackendAPI || name == BackendREST || name == BackendReplay
+}
+
 type Client struct {
 	Address  string
 	Username string
 	Password string
 	Timeout  time.Duration
-	client   *routeros.Client
+	TLS      TLSConfig
+
+	// MaxConcurrency limits the number of commands running at the same time.
+	// Values above 1 switch the connection to the tagged asynchronous mode of
+	// the RouterOS API; 1 or less runs commands strictly one after another.
+	MaxConcurrency int
+
+	// Backend selects how commands are sent to the router: BackendAPI (the
+	// default when empty), BackendREST or BackendReplay.
+	Backend string
+
+	// Fixture holds the replies served by the replay backend.
+	Fixture *Fixture
+
+	// Recorder, if set, captures every command and its reply.
+	Recorder *Recorder
+
+	mu          sync.Mutex
+	client      *routeros.Client
+	rest        *restTransport
+	asyncCancel context.CancelFunc
+	sem         chan struct{}
 }
 
 func NewClient(address, username, password string, timeout time.Duration) *Client {
@@ -28,102 +76,349 @@ func NewClient(address, username, password string, timeout time.Duration) *Clien
 		timeout = DefaultTimeout
 	}
 	return &Client{
-		Address:  address,
-		Username: username,
-		Password: password,
-		Timeout:  timeout,
+		Address:        address,
+		Username:       username,
+		Password:       password,
+		Timeout:        timeout,
+		MaxConcurrency: 1,
 	}
 }
 
-func (c *Client) Connect() error {
+// Target returns the address of the router.
+func (c *Client) Target() string {
+	return c.Address
+}
+
+// Connect dials and logs in to the router unless a connection is already
+// established. The dial is bounded by both ctx and the client timeout.
+func (c *Client) Connect(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != nil || c.rest != nil {
+		return nil
+	}
+	switch c.Backend {
+	case BackendREST:
+		return c.connectRESTLocked(ctx)
+	case BackendReplay:
+		if c.Fixture == nil {
+			return errors.New("replay backend without fixture")
+		}
+		return nil
+	}
+	return c.connectLocked(ctx)
+}
+
+// Connected reports whether the client currently holds an open connection.
+func (c *Client) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client != nil || c.rest != nil || (c.Backend == BackendReplay && c.Fixture != nil)
+}
+
+func (c *Client) connectLocked(ctx context.Context) error {
+	dialCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
 	addr := c.Address
 	_, _, err := net.SplitHostPort(addr)
 	if err != nil {
-		addr = net.JoinHostPort(addr, defaultMikrotikAPIPort)
+		addr = net.JoinHostPort(addr, DefaultPort(c.TLS.Enabled))
 	}
 
-	log.Printf("Connecting to MikroTik router at %s with timeout %s...", addr, c.Timeout)
-	client, err := routeros.DialTimeout(addr, c.Username, c.Password, c.Timeout)
+	var conn net.Conn
+	if c.TLS.Enabled {
+		tlsConfig, tlsErr := newTLSConfig(c.TLS)
+		if tlsErr != nil {
+			log.Printf("Error preparing TLS configuration for MikroTik router %s: %v", addr, tlsErr)
+			return tlsErr
+		}
+		log.Printf("Connecting to MikroTik router at %s using TLS with timeout %s...", addr, c.Timeout)
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(dialCtx, "tcp", addr)
+	} else {
+		log.Printf("Connecting to MikroTik router at %s with timeout %s...", addr, c.Timeout)
+		conn, err = new(net.Dialer).DialContext(dialCtx, "tcp", addr)
+	}
 	if err != nil {
+		dialFailures.Inc()
 		log.Printf("Error dialing MikroTik router %s: %v", addr, err)
 		return err
 	}
+
+	client, err := c.login(dialCtx, conn)
+	if err != nil {
+		dialFailures.Inc()
+		log.Printf("Error logging in to MikroTik router %s: %v", addr, err)
+		return err
+	}
+
+	c.closeLocked()
 	c.client = client
+
+	if c.MaxConcurrency > 1 {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.asyncCancel = cancel
+		errC := client.AsyncContext(ctx)
+		go func() {
+			if err := <-errC; err != nil {
+				log.Printf("Asynchronous API session to %s ended: %v", c.Address, err)
+			}
+		}()
+	}
 	return nil
 }
 
+// login authenticates on conn. The library only honours the context in
+// asynchronous mode, so the connection is closed to abort a stuck login.
+func (c *Client) login(ctx context.Context, conn net.Conn) (*routeros.Client, error) {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+
+	client, err := routeros.NewClient(conn)
+	if err == nil {
+		err = client.LoginContext(ctx, c.Username, c.Password)
+	}
+	if !stop() {
+		err = ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
 func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closeLocked()
+}
+
+func (c *Client) closeLocked() {
+	if c.asyncCancel != nil {
+		c.asyncCancel()
+		c.asyncCancel = nil
+	}
 	if c.client != nil {
 		log.Printf("Closing connection to MikroTik router %s", c.Address)
 		c.client.Close()
 		c.client = nil
 	}
+	if c.rest != nil {
+		c.rest.http.CloseIdleConnections()
+		c.rest = nil
+	}
 }
 
-func (c *Client) Run(cmd ...string) (*routeros.Reply, error) {
+// session returns the current connection, dialing a new one if needed, and
+// the semaphore bounding the number of commands in flight. fresh reports
+// whether the connection was established by this call.
+func (c *Client) session(ctx context.Context) (conn *routeros.Client, sem chan struct{}, fresh bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sem == nil {
+		c.sem = make(chan struct{}, max(c.MaxConcurrency, 1))
+	}
 	if c.client == nil {
-		if err := c.Connect(); err != nil {
-			return nil, err
+		if err := c.connectLocked(ctx); err != nil {
+			return nil, nil, false, err
 		}
+		fresh = true
 	}
+	return c.client, c.sem, fresh, nil
+}
 
-	replyCh := make(chan *routeros.Reply, 1)
-	errCh := make(chan error, 1)
+// discard closes conn if it is still the active connection of the client.
+func (c *Client) discard(conn *routeros.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client == conn {
+		c.closeLocked()
+	}
+}
 
-	go func() {
-		reply, err := c.client.Run(cmd...)
-		if err != nil {
-			errCh <- err
-			return
-		}
-		replyCh <- reply
-	}()
+// Run runs a command given as separate words. See RunArgs.
+func (c *Client) Run(ctx context.Context, cmd ...string) (*routeros.Reply, error) {
+	return c.RunArgs(ctx, cmd)
+}
 
-	select {
-	case reply := <-replyCh:
-		return reply, nil
-	case err := <-errCh:
-		log.Printf("Error running command on %s: %v", c.Address, err)
-		c.Close()
-		return nil, err
-	case <-time.After(c.Timeout):
-		log.Printf("Timeout running command on %s", c.Address)
-		c.Close()
-		return nil, fmt.Errorf("command timeout after %s", c.Timeout)
+// RunArgs runs a command and waits for its reply. The command is aborted when
+// ctx is done or the client timeout expires, whichever comes first. Aborting a
+// command closes the connection; the next command reconnects. If a connection
+// that was kept from earlier commands turns out to be broken, the command is
+// retried once on a new connection.
+func (c *Client) RunArgs(ctx context.Context, args []string) (*routeros.Reply, error) {
+	reply, err := c.run(ctx, args)
+	if c.Recorder != nil {
+		c.Recorder.record(args, reply, err)
 	}
+	return reply, err
 }
 
-func (c *Client) RunArgs(args []string) (*routeros.Reply, error) {
-	if c.client == nil {
-		if err := c.Connect(); err != nil {
-			return nil, err
+// count runs a print command with count-only and returns the number of
+// matching items, which RouterOS reports in the ret attribute of !done.
+func (c *Client) count(ctx context.Context, path string, queries ...string) (uint64, error) {
+	reply, err := c.RunArgs(ctx, append([]string{path, "=count-only="}, queries...))
+	if err != nil {
+		return 0, err
+	}
+	if reply.Done == nil || reply.Done.Map["ret"] == "" {
+		return 0, fmt.Errorf("no count in reply to %s", path)
+	}
+	n, err := strconv.ParseUint(reply.Done.Map["ret"], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid count in reply to %s: %w", path, err)
+	}
+	return n, nil
+}
+
+func (c *Client) run(ctx context.Context, args []string) (*routeros.Reply, error) {
+	switch c.Backend {
+	case BackendREST:
+		return c.runREST(ctx, args)
+	case BackendReplay:
+		return c.runReplay(ctx, args)
+	}
+	reply, err, retry := c.runOnce(ctx, args)
+	if retry {
+		log.Printf("Reconnecting to %s to retry command %s", c.Address, commandPath(args))
+		reply, err, _ = c.runOnce(ctx, args)
+	}
+	return reply, err
+}
+
+func (c *Client) runOnce(ctx context.Context, args []string) (reply *routeros.Reply, err error, retry bool) {
+	if err := ctx.Err(); err != nil {
+		return nil, c.abortError(commandPath(args), err), false
+	}
+
+	conn, sem, fresh, err := c.session(ctx)
+	if err != nil {
+		return nil, err, false
+	}
+	reply, err, broken := c.runOn(ctx, conn, sem, args)
+	return reply, err, broken && !fresh
+}
+
+// runOn runs a command on conn. broken reports whether the connection failed
+// and was closed, as opposed to the router answering with a !trap.
+func (c *Client) runOn(ctx context.Context, conn *routeros.Client, sem chan struct{}, args []string) (reply *routeros.Reply, err error, broken bool) {
+	path := commandPath(args)
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, c.abortError(path, ctx.Err()), false
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	if conn.IsAsync() {
+		var aborted error
+		reply, err, aborted = runAsync(cmdCtx, conn, sem, args)
+		if aborted != nil {
+			// The other commands on the session are not affected.
+			return nil, c.abortError(path, aborted), false
+		}
+	} else {
+		// The synchronous mode of the library ignores the context, so the
+		// command is aborted by closing the connection underneath it.
+		stop := context.AfterFunc(cmdCtx, func() { c.discard(conn) })
+		reply, err = conn.RunArgs(args)
+		stop()
+		<-sem
+		if cmdErr := cmdCtx.Err(); cmdErr != nil {
+			c.discard(conn)
+			return nil, c.abortError(path, cmdErr), false
 		}
 	}
 
-	replyCh := make(chan *routeros.Reply, 1)
-	errCh := make(chan error, 1)
+	if err != nil {
+		log.Printf("Error running command %v on %s: %v", args, c.Address, err)
+		// A !trap reply leaves the session usable; anything else means the
+		// connection is broken and must be re-established.
+		var deviceErr *routeros.DeviceError
+		if errors.As(err, &deviceErr) {
+			return nil, err, false
+		}
+		c.discard(conn)
+		return nil, err, true
+	}
+	return reply, nil, false
+}
 
+// runAsync runs a tagged command on an asynchronous connection and releases
+// its slot of sem once the router has answered. aborted is the error of ctx if
+// it ended first.
+//
+// The library aborts the reads of the whole session when the context of a
+// command ends, so the command runs without one and is left to finish in the
+// background instead: only the caller stops waiting for it.
+func runAsync(ctx context.Context, conn *routeros.Client, sem chan struct{}, args []string) (reply *routeros.Reply, err, aborted error) {
+	type result struct {
+		reply *routeros.Reply
+		err   error
+	}
+	done := make(chan result, 1)
 	go func() {
-		reply, err := c.client.RunArgs(args)
-		if err != nil {
-			errCh <- err
-			return
-		}
-		replyCh <- reply
+		defer func() { <-sem }()
+		reply, err := conn.RunArgsContext(context.Background(), args)
+		done <- result{reply, err}
 	}()
 
 	select {
-	case reply := <-replyCh:
-		return reply, nil
-	case err := <-errCh:
-		log.Printf("Error running command with args on %s: %v", c.Address, err)
-		c.Close()
-		return nil, err
-	case <-time.After(c.Timeout):
-		log.Printf("Timeout running command with args on %s", c.Address)
-		c.Close()
-		return nil, fmt.Errorf("command timeout after %s", c.Timeout)
+	case r := <-done:
+		return r.reply, r.err, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+// ping runs a cheap command to check that the open connection still works.
+// Unlike Run it neither dials a new connection nor retries, and a broken
+// connection is closed.
+func (c *Client) ping(ctx context.Context) error {
+	if c.Backend == BackendREST || c.Backend == BackendReplay {
+		if !c.Connected() {
+			return errors.New("not connected")
+		}
+		_, err := c.run(ctx, []string{"/system/identity/print"})
+		return err
+	}
+
+	c.mu.Lock()
+	conn := c.client
+	if c.sem == nil {
+		c.sem = make(chan struct{}, max(c.MaxConcurrency, 1))
+	}
+	sem := c.sem
+	c.mu.Unlock()
+	if conn == nil {
+		return errors.New("not connected")
 	}
+	_, err, _ := c.runOn(ctx, conn, sem, []string{"/system/identity/print"})
+	return err
+}
+
+// abortError records a command that was aborted because its context ended and
+// returns the error reported to the caller.
+func (c *Client) abortError(path string, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		commandsTimedOut.WithLabelValues(path).Inc()
+		log.Printf("Timeout running command %s on %s", path, c.Address)
+		return fmt.Errorf("command %s timed out: %w", path, err)
+	}
+	commandsCancelled.WithLabelValues(path).Inc()
+	log.Printf("Command %s on %s was cancelled", path, c.Address)
+	return fmt.Errorf("command %s cancelled: %w", path, err)
+}
+
+// commandPath returns the menu path of a command, e.g. /interface/print.
+func commandPath(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
 }
 
 type SystemResource struct {
@@ -166,21 +461,65 @@ type InterfaceStat struct {
 }
 
 type BGPPeerStat struct {
-	Name          string
-	Instance      string
-	RemoteAddress string
-	RemoteAS      string
-	LocalAddress  string
-	LocalRole     string
-	RemoteRole    string
-	State         string
-	Uptime        time.Duration
-	PrefixCount   uint64
-	UpdatesSent   uint64
-	UpdatesRecv   uint64
-	WithdrawsSent uint64
-	WithdrawsRecv uint64
-	Disabled      bool
+	Name            string
+	Instance        string
+	RemoteAddress   string
+	RemoteAS        string
+	LocalAddress    string
+	LocalRole       string
+	RemoteRole      string
+	AddressFamilies string
+	State           string
+	Uptime          time.Duration
+	PrefixCount     uint64
+	UpdatesSent     uint64
+	UpdatesRecv     uint64
+	WithdrawsSent   uint64
+	WithdrawsRecv   uint64
+	Disabled        bool
+}
+
+// BGPSessionStat is a BGP session of RouterOS 7 from /routing/bgp/session,
+// joined with the /routing/bgp/connection it belongs to.
+type BGPSessionStat struct {
+	Name             string
+	Connection       string
+	Template         string
+	RemoteAddress    string
+	RemoteAS         string
+	RemoteID         string
+	LocalAddress     string
+	LocalAS          string
+	LocalID          string
+	LocalRole        string
+	AddressFamilies  string
+	Established      bool
+	Prefixes         map[string]BGPPrefixCounts
+	Uptime           time.Duration
+	HoldTime         time.Duration
+	KeepaliveTime    time.Duration
+	PrefixCount      uint64
+	MessagesSent     uint64
+	MessagesReceived uint64
+	BytesSent        uint64
+	BytesReceived    uint64
+}
+
+// BGPConnection is a BGP connection of RouterOS 7 from /routing/bgp/connection.
+type BGPConnection struct {
+	Name     string
+	Disabled bool
+	// Connect is false for connections that only listen for the remote to
+	// initiate the session.
+	Connect bool
+}
+
+// BGPPrefixCounts are the prefixes of one address family received in a BGP
+// session. Filtered prefixes were rejected by the input filter.
+type BGPPrefixCounts struct {
+	Received uint64
+	Accepted uint64
+	Filtered uint64
 }
 
 type PPPUserStat struct {
@@ -203,8 +542,8 @@ type SystemHealth struct {
 	FanSpeed         uint64
 }
 
-func (c *Client) GetSystemResources() (*SystemResource, error) {
-	reply, err := c.Run("/system/resource/print")
+func (c *Client) GetSystemResources(ctx context.Context) (*SystemResource, error) {
+	reply, err := c.Run(ctx, "/system/resource/print")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get system resources: %w", err)
 	}
@@ -256,8 +595,8 @@ func (c *Client) GetSystemResources() (*SystemResource, error) {
 	}, nil
 }
 
-func (c *Client) GetRouterboard() (*Routerboard, error) {
-	reply, err := c.Run("/system/routerboard/print")
+func (c *Client) GetRouterboard(ctx context.Context) (*Routerboard, error) {
+	reply, err := c.Run(ctx, "/system/routerboard/print")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get routerboard info: %w", err)
 	}
@@ -284,54 +623,58 @@ func parseMikrotikDuration(durationStr string) (time.Duration, error) {
 	}
 
 	var totalDuration time.Duration
-	var currentVal strings.Builder
-	var unit rune
-
-	for _, r := range durationStr {
-		if r >= '0' && r <= '9' || r == '.' {
-			currentVal.WriteRune(r)
-		} else {
-			unit = r
-			valStr := currentVal.String()
-			if valStr == "" {
-				return 0, fmt.Errorf("invalid duration format near unit '%c' in '%s'", unit, durationStr)
-			}
+	rest := durationStr
+	for rest != "" {
+		i := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i < 0 {
+			return 0, fmt.Errorf("trailing number without unit in duration '%s'", durationStr)
+		}
+		valStr := rest[:i]
+		rest = rest[i:]
+		// RouterOS 7 adds milliseconds and microseconds, e.g. "4s70ms".
+		j := strings.IndexFunc(rest, func(r rune) bool { return r >= '0' && r <= '9' || r == '.' })
+		if j < 0 {
+			j = len(rest)
+		}
+		unitStr := rest[:j]
+		rest = rest[j:]
+		if valStr == "" {
+			return 0, fmt.Errorf("invalid duration format near unit '%s' in '%s'", unitStr, durationStr)
+		}
 
-			var val int64
-			var err error
-			if unit == 's' {
-				fVal, fErr := strconv.ParseFloat(valStr, 64)
-				if fErr == nil {
-					totalDuration += time.Duration(fVal * float64(time.Second))
-					currentVal.Reset()
-					continue
-				}
-			}
+		var unit time.Duration
+		switch unitStr {
+		case "w":
+			unit = 7 * 24 * time.Hour
+		case "d":
+			unit = 24 * time.Hour
+		case "h":
+			unit = time.Hour
+		case "m":
+			unit = time.Minute
+		case "s":
+			unit = time.Second
+		case "ms":
+			unit = time.Millisecond
+		case "us":
+			unit = time.Microsecond
+		default:
+			return 0, fmt.Errorf("unknown duration unit '%s' in '%s'", unitStr, durationStr)
+		}
 
-			val, err = strconv.ParseInt(valStr, 10, 64)
+		if unit <= time.Second {
+			fVal, err := strconv.ParseFloat(valStr, 64)
 			if err != nil {
 				return 0, fmt.Errorf("could not parse value '%s' in duration '%s': %w", valStr, durationStr, err)
 			}
-
-			switch unit {
-			case 'w':
-				totalDuration += time.Duration(val) * 7 * 24 * time.Hour
-			case 'd':
-				totalDuration += time.Duration(val) * 24 * time.Hour
-			case 'h':
-				totalDuration += time.Duration(val) * time.Hour
-			case 'm':
-				totalDuration += time.Duration(val) * time.Minute
-			case 's':
-				totalDuration += time.Duration(val) * time.Second
-			default:
-				return 0, fmt.Errorf("unknown duration unit '%c' in '%s'", unit, durationStr)
-			}
-			currentVal.Reset()
+			totalDuration += time.Duration(fVal * float64(unit))
+			continue
 		}
-	}
-	if currentVal.Len() > 0 {
-		return 0, fmt.Errorf("trailing number without unit in duration '%s'", durationStr)
+		val, err := strconv.ParseInt(valStr, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("could not parse value '%s' in duration '%s': %w", valStr, durationStr, err)
+		}
+		totalDuration += time.Duration(val) * unit
 	}
 
 	return totalDuration, nil
@@ -348,15 +691,21 @@ func parseBytes(byteStr string) (uint64, error) {
 	return bytes, nil
 }
 
+// parseUint parses a counter, treating missing or malformed values as zero.
+func parseUint(s string) uint64 {
+	n, _ := strconv.ParseUint(s, 10, 64)
+	return n
+}
+
 func parseBool(boolStr string) bool {
 	return strings.ToLower(boolStr) == "true"
 }
 
-func (c *Client) GetInterfaceStats() ([]InterfaceStat, error) {
+func (c *Client) GetInterfaceStats(ctx context.Context) ([]InterfaceStat, error) {
 	start := time.Now()
 	log.Printf("DEBUG: Starting initial interface list for %s", c.Address)
 	// Request only name and type to speed up initial interface list
-	initialReply, err := c.RunArgs([]string{"/interface/print", "without-paging", "=.proplist=name,type"})
+	initialReply, err := c.RunArgs(ctx, []string{"/interface/print", "without-paging", "=.proplist=name,type"})
 	log.Printf("DEBUG: Completed initial interface list for %s in %s", c.Address, time.Since(start))
 	if err != nil {
 		log.Printf("DEBUG: initial interface list failed for %s: %v", c.Address, err)
@@ -400,7 +749,7 @@ func (c *Client) GetInterfaceStats() ([]InterfaceStat, error) {
 		interfaceNames = append(interfaceNames, s.Name)
 	}
 
-	detailReply, detailErr := c.Run("/interface/print", "detail", "without-paging")
+	detailReply, detailErr := c.Run(ctx, "/interface/print", "detail", "without-paging")
 	if detailErr != nil {
 		log.Printf("Warning: Failed to get detailed interface info for %s: %v. Proceeding without comment/mac/status.", c.Address, detailErr)
 	} else {
@@ -423,16 +772,22 @@ func (c *Client) GetInterfaceStats() ([]InterfaceStat, error) {
 	log.Printf("DEBUG: Attempting to fetch stats for interfaces: %v", monitoredNames)
 
 	statsCmd := []string{"/interface/print", "stats", "without-paging"}
-	statsReply, statsErr := c.RunArgs(statsCmd)
+	statsReply, statsErr := c.RunArgs(ctx, statsCmd)
 
 	if statsErr != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to get interface traffic counters: %w", ctx.Err())
+		}
 		log.Printf("Warning: Failed to get interface traffic counters using '/interface/print stats' for %s: %v. Attempting per-interface monitor-traffic fallback.", c.Address, statsErr)
 		for name, statPtr := range ifaceMap {
 			if statPtr == nil {
 				continue
 			}
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to get interface traffic counters: %w", ctx.Err())
+			}
 			args := []string{"/interface/monitor-traffic", "interface=" + name, "once", "without-paging", "=.proplist=rx-byte,tx-byte,rx-packet,tx-packet,rx-error,tx-error,rx-drop,tx-drop"}
-			monitorReply, err := c.RunArgs(args)
+			monitorReply, err := c.RunArgs(ctx, args)
 			if err != nil {
 				log.Printf("Warning: monitor-traffic failed for interface %s: %v", name, err)
 				continue
@@ -471,7 +826,7 @@ func (c *Client) GetInterfaceStats() ([]InterfaceStat, error) {
 		log.Printf("INFO: Combined /interface/print fallback for %s", c.Address)
 		combinedArgs := []string{"/interface/print", "without-paging", "=.proplist=name,rx-byte,tx-byte,rx-packet,tx-packet,rx-error,tx-error,rx-drop,tx-drop"}
 		startCombined := time.Now()
-		combinedReply, combinedErr := c.RunArgs(combinedArgs)
+		combinedReply, combinedErr := c.RunArgs(ctx, combinedArgs)
 		log.Printf("DEBUG: Completed combined interface list with stats for %s in %s", c.Address, time.Since(startCombined))
 		if combinedErr != nil {
 			log.Printf("Error: combined /interface/print fallback failed for %s: %v", c.Address, combinedErr)
@@ -604,8 +959,8 @@ func (c *Client) GetInterfaceStats() ([]InterfaceStat, error) {
 	return stats, nil
 }
 
-func (c *Client) GetSystemHealth() (*SystemHealth, error) {
-	reply, err := c.Run("/system/health/print")
+func (c *Client) GetSystemHealth(ctx context.Context) (*SystemHealth, error) {
+	reply, err := c.Run(ctx, "/system/health/print")
 	if err != nil {
 		if strings.Contains(err.Error(), "no such command") || strings.Contains(err.Error(), "unknown command name") {
 			log.Printf("Info: /system/health/print command not found on %s. Temperature monitoring might not be supported.", c.Address)
@@ -619,6 +974,9 @@ func (c *Client) GetSystemHealth() (*SystemHealth, error) {
 		return nil, nil
 	}
 	healthData := reply.Re[0]
+	if c.capabilities(ctx).HealthList() {
+		healthData = flattenHealthList(reply.Re)
+	}
 
 	parseFloat := func(key string) float64 {
 		valStr := healthData.Map[key]
@@ -670,3 +1028,15 @@ func (c *Client) GetSystemHealth() (*SystemHealth, error) {
 
 	return health, nil
 }
+
+// flattenHealthList converts the health sensors of RouterOS 7, reported as one
+// item per sensor with name and value, into the single item of RouterOS 6.
+func flattenHealthList(items []*proto.Sentence) *proto.Sentence {
+	flat := &proto.Sentence{Map: make(map[string]string, len(items))}
+	for _, item := range items {
+		if name := item.Map["name"]; name != "" {
+			flat.Map[name] = item.Map["value"]
+		}
+	}
+	return flat
+}
@@ -0,0 +1,105 @@
+package mikrotik_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/taihen/ros-exporter/pkg/mikrotik"
+	"github.com/taihen/ros-exporter/pkg/mikrotik/fakeapi"
+)
+
+// slowFixture answers /system/resource/print only after a second.
+var slowFixture = &mikrotik.Fixture{Commands: []mikrotik.FixtureCommand{
+	{Command: "/system/identity/print", Replies: []map[string]string{{"name": "router"}}},
+	{Command: "/system/resource/print", Replies: []map[string]string{{"uptime": "1d"}}, Delay: time.Second},
+}}
+
+func TestAsyncTimeoutKeepsSession(t *testing.T) {
+	srv, err := fakeapi.NewServer(slowFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	client := mikrotik.NewClient(srv.Addr(), "prometheus", "secret", 300*time.Millisecond)
+	client.MaxConcurrency = 4
+	defer client.Close()
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := client.Run(ctx, "/system/resource/print"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("slow command returned %v, want a timeout", err)
+		}
+	}()
+	// The other commands on the session succeed while the slow one runs and
+	// after it timed out.
+	for range 5 {
+		if _, err := client.Run(ctx, "/system/identity/print"); err != nil {
+			t.Errorf("command next to the slow one failed: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	wg.Wait()
+
+	if !client.Connected() {
+		t.Error("timeout closed the session")
+	}
+	if got := srv.Logins(); got != 1 {
+		t.Errorf("router saw %d logins, want 1", got)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		concurrency int
+	}{
+		{"sequential", 1},
+		{"async", 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, err := fakeapi.NewServer(slowFixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer srv.Close()
+			before := runtime.NumGoroutine()
+
+			client := mikrotik.NewClient(srv.Addr(), "prometheus", "secret", 5*time.Second)
+			client.MaxConcurrency = tc.concurrency
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(200*time.Millisecond, cancel)
+
+			start := time.Now()
+			_, err = client.Run(ctx, "/system/resource/print")
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("cancelled command returned after %s", elapsed)
+			}
+			if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "/system/resource/print cancelled") {
+				t.Errorf("cancelled command returned %v", err)
+			}
+			client.Close()
+
+			// The goroutines of the session end once it is closed and the
+			// router has answered the delayed command.
+			deadline := time.Now().Add(3 * time.Second)
+			for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+				time.Sleep(50 * time.Millisecond)
+			}
+			if after := runtime.NumGoroutine(); after > before {
+				t.Errorf("%d goroutines before the command, %d after", before, after)
+			}
+		})
+	}
+}
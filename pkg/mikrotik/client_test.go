@@ -0,0 +1,29 @@
+package mikrotik
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMikrotikDuration(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"1w2d3h4m5s": 9*24*time.Hour + 3*time.Hour + 4*time.Minute + 5*time.Second,
+		"3d4h5m6s":   3*24*time.Hour + 4*time.Hour + 5*time.Minute + 6*time.Second,
+		"1m30s":      90 * time.Second,
+		"1.5s":       1500 * time.Millisecond,
+		"4s70ms":     4*time.Second + 70*time.Millisecond,
+		"200ms":      200 * time.Millisecond,
+		"300us":      300 * time.Microsecond,
+	} {
+		got, err := parseMikrotikDuration(in)
+		if err != nil || got != want {
+			t.Errorf("parseMikrotikDuration(%q) = %s, %v; want %s", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"", "10", "5x", "s", "1.5m"} {
+		if _, err := parseMikrotikDuration(in); err == nil {
+			t.Errorf("parseMikrotikDuration(%q) succeeded, want an error", in)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package mikrotik
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetConnectionTrackingZeroCounts(t *testing.T) {
+	const tracking, table = "/ip/firewall/connection/tracking/print", "/ip/firewall/connection/print"
+	for _, tc := range []struct {
+		name     string
+		commands []FixtureCommand
+		udp      uint64
+	}{
+		{"inactive", []FixtureCommand{
+			{Command: tracking, Replies: []map[string]string{{"enabled": "auto", "active-ipv4": "false", "total-entries": "0"}}},
+		}, 0},
+		{"no tcp", []FixtureCommand{
+			{Command: tracking, Replies: []map[string]string{{"enabled": "yes", "active-ipv4": "true", "total-entries": "3"}}},
+			{Command: table, Args: []string{"=count-only=", "?protocol=udp"}, Done: map[string]string{"ret": "3"}},
+			{Command: table, Args: []string{"=count-only="}, Done: map[string]string{"ret": "0"}},
+		}, 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewClient("conntrack-test", "", "", DefaultTimeout)
+			client.Backend = BackendReplay
+			client.Fixture = &Fixture{Commands: tc.commands}
+			ct, err := client.GetConnectionTracking(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, protocol := range conntrackProtocols {
+				want := uint64(0)
+				if protocol == "udp" {
+					want = tc.udp
+				}
+				if got, ok := ct.Protocols[protocol]; !ok || got != want {
+					t.Errorf("Protocols[%q] = %d, %v; want %d", protocol, got, ok, want)
+				}
+			}
+			for _, state := range conntrackTCPStates {
+				if got, ok := ct.TCPStates[state]; !ok || got != 0 {
+					t.Errorf("TCPStates[%q] = %d, %v; want 0", state, got, ok)
+				}
+			}
+		})
+	}
+}
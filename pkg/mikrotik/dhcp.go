@@ -0,0 +1,160 @@
+package mikrotik
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// DHCPLeaseStatuses are the lease statuses counted per DHCP server.
+var DHCPLeaseStatuses = []string{"bound", "waiting", "offered", "busy"}
+
+// DHCPServer is a DHCP server from /ip/dhcp-server with the number of its
+// leases and the utilisation of its address pool.
+type DHCPServer struct {
+	Name        string
+	Interface   string
+	AddressPool string
+	LeaseTime   time.Duration
+	Disabled    bool
+	// Leases counts the leases of the server by status.
+	Leases map[string]uint64
+	// PoolSize and PoolUsed are the number of addresses in the address pool
+	// and in use. Both are zero for servers without a pool (static-only) and
+	// if the pool could not be read.
+	PoolSize uint64
+	PoolUsed uint64
+}
+
+// DHCPLease is a lease from /ip/dhcp-server/lease.
+type DHCPLease struct {
+	Server       string
+	Address      string
+	MACAddress   string
+	HostName     string
+	Status       string
+	Dynamic      bool
+	ExpiresAfter time.Duration
+}
+
+// GetDHCPServers fetches the DHCP servers and counts their leases by status
+// and the used addresses of their pools with count-only queries, so the lease
+// table is not transferred. If the size or usage of a pool cannot be
+// determined, the pool utilisation of its servers is left out.
+func (c *Client) GetDHCPServers(ctx context.Context) ([]DHCPServer, error) {
+	if !c.capabilities(ctx).HasDHCP() {
+		log.Printf("DHCP package is disabled on %s. Skipping DHCP metrics.", c.Address)
+		return []DHCPServer{}, nil
+	}
+
+	reply, err := c.Run(ctx, "/ip/dhcp-server/print", "without-paging")
+	if err != nil {
+		if strings.Contains(err.Error(), "no such command") {
+			log.Printf("DHCP feature might be disabled on %s. Skipping DHCP metrics.", c.Address)
+			return []DHCPServer{}, nil
+		}
+		return nil, fmt.Errorf("failed to get DHCP servers: %w", err)
+	}
+	if len(reply.Re) == 0 {
+		return []DHCPServer{}, nil
+	}
+
+	pools, err := c.getIPPools(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		log.Printf("Warning: Could not get IP pools from %s, skipping DHCP pool utilisation: %v", c.Address, err)
+	}
+	poolSizes := make(map[string]uint64, len(pools))
+	for _, pool := range pools {
+		poolSizes[pool.Name] = pool.Size
+	}
+
+	servers := make([]DHCPServer, 0, len(reply.Re))
+	for _, re := range reply.Re {
+		server := DHCPServer{
+			Name:        re.Map["name"],
+			Interface:   re.Map["interface"],
+			AddressPool: re.Map["address-pool"],
+			Disabled:    parseBool(re.Map["disabled"]),
+			Leases:      make(map[string]uint64, len(DHCPLeaseStatuses)),
+		}
+		if server.Name == "" {
+			log.Printf("Warning: Skipping DHCP server without name: %v", re.Map)
+			continue
+		}
+		if value := re.Map["lease-time"]; value != "" {
+			if server.LeaseTime, err = parseMikrotikDuration(value); err != nil {
+				log.Printf("Warning: Could not parse lease time '%s' of DHCP server '%s': %v", value, server.Name, err)
+			}
+		}
+
+		total, err := c.count(ctx, "/ip/dhcp-server/lease/print", "?server="+server.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count leases of DHCP server %s: %w", server.Name, err)
+		}
+		for _, status := range DHCPLeaseStatuses {
+			n := uint64(0)
+			if total > 0 {
+				n, err = c.count(ctx, "/ip/dhcp-server/lease/print", "?server="+server.Name, "?status="+status)
+				if err != nil {
+					return nil, fmt.Errorf("failed to count %s leases of DHCP server %s: %w", status, server.Name, err)
+				}
+			}
+			server.Leases[status] = n
+		}
+
+		if size, ok := poolSizes[server.AddressPool]; ok {
+			used, err := c.count(ctx, "/ip/pool/used/print", "?pool="+server.AddressPool)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, fmt.Errorf("failed to count used addresses of IP pool %s: %w", server.AddressPool, err)
+				}
+				log.Printf("Warning: Could not count used addresses of IP pool '%s' on %s: %v", server.AddressPool, c.Address, err)
+			} else {
+				server.PoolSize = size
+				server.PoolUsed = used
+			}
+		}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+// GetDHCPLeases fetches every lease of the DHCP servers.
+func (c *Client) GetDHCPLeases(ctx context.Context) ([]DHCPLease, error) {
+	if !c.capabilities(ctx).HasDHCP() {
+		return []DHCPLease{}, nil
+	}
+
+	reply, err := c.Run(ctx, "/ip/dhcp-server/lease/print", "without-paging",
+		"=.proplist=server,address,mac-address,host-name,status,dynamic,expires-after")
+	if err != nil {
+		if strings.Contains(err.Error(), "no such command") {
+			return []DHCPLease{}, nil
+		}
+		return nil, fmt.Errorf("failed to get DHCP leases: %w", err)
+	}
+
+	leases := make([]DHCPLease, 0, len(reply.Re))
+	for _, re := range reply.Re {
+		lease := DHCPLease{
+			Server:     re.Map["server"],
+			Address:    re.Map["address"],
+			MACAddress: re.Map["mac-address"],
+			HostName:   re.Map["host-name"],
+			Status:     re.Map["status"],
+			Dynamic:    parseBool(re.Map["dynamic"]),
+		}
+		if value := re.Map["expires-after"]; value != "" {
+			if lease.ExpiresAfter, err = parseMikrotikDuration(value); err != nil {
+				log.Printf("Warning: Could not parse expiry '%s' of DHCP lease '%s': %v", value, lease.Address, err)
+			}
+		}
+		leases = append(leases, lease)
+	}
+	return leases, nil
+}
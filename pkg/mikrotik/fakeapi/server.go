@@ -0,0 +1,290 @@
+// Package fakeapi implements an in-process stand-in for the RouterOS API
+// service. It speaks the API sentence protocol on a local listener and answers
+// commands from a scripted mikrotik.Fixture, so that clients and collectors
+// can be tested without a router.
+package fakeapi
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-routeros/routeros/v3/proto"
+	"github.com/taihen/ros-exporter/pkg/mikrotik"
+)
+
+// NoSuchCommand is the trap message sent for commands missing from the fixture.
+const NoSuchCommand = "no such command"
+
+// Server is a fake RouterOS API service.
+type Server struct {
+	// Username and Password are the credentials accepted by /login. Empty
+	// values accept any login.
+	Username string
+	Password string
+
+	fixture  *mikrotik.Fixture
+	listener net.Listener
+
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{}
+	logins   int
+	commands map[string]int
+	closed   bool
+
+	wg sync.WaitGroup
+}
+
+// NewServer starts a server on a random port of the loopback interface that
+// answers commands from fixture.
+func NewServer(fixture *mikrotik.Fixture) (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		fixture:  fixture,
+		listener: ln,
+		conns:    make(map[net.Conn]struct{}),
+		commands: make(map[string]int),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the host:port the server listens on.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Logins returns the number of successful logins.
+func (s *Server) Logins() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.logins
+}
+
+// Commands returns how often each command path has been received, excluding /login.
+func (s *Server) Commands() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.commands)
+}
+
+// DropConnections closes all open client connections, like a router reboot
+// would, while the server keeps accepting new ones.
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// Close stops the server and closes all connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	err := s.listener.Close()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+			conn.Close()
+		}()
+	}
+}
+
+// handle serves a single connection. Every command after the login is
+// answered in its own goroutine so that tagged commands of the asynchronous
+// mode can be answered out of order.
+func (s *Server) handle(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	w := proto.NewWriter(conn)
+
+	loggedIn := false
+	var pending sync.WaitGroup
+	defer pending.Wait()
+
+	for {
+		words, err := readSentence(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Printf("fakeapi: reading sentence: %v", err)
+			}
+			return
+		}
+		if len(words) == 0 {
+			continue
+		}
+		tag := ""
+		for _, word := range words[1:] {
+			if t, ok := strings.CutPrefix(word, ".tag="); ok {
+				tag = t
+			}
+		}
+
+		if !loggedIn {
+			if words[0] != "/login" {
+				writeTrap(w, tag, "not logged in")
+				continue
+			}
+			if !s.checkLogin(words[1:]) {
+				writeTrap(w, tag, "invalid user name or password (6)")
+				return
+			}
+			s.mu.Lock()
+			s.logins++
+			s.mu.Unlock()
+			loggedIn = true
+			writeDone(w, tag, nil)
+			continue
+		}
+
+		s.mu.Lock()
+		s.commands[words[0]]++
+		s.mu.Unlock()
+
+		cmd, ok := s.fixture.Lookup(words)
+		if !ok {
+			writeTrap(w, tag, NoSuchCommand)
+			continue
+		}
+		pending.Add(1)
+		go func() {
+			defer pending.Done()
+			if cmd.Delay > 0 {
+				time.Sleep(cmd.Delay)
+			}
+			reply(w, tag, cmd)
+		}()
+	}
+}
+
+func (s *Server) checkLogin(words []string) bool {
+	if s.Username == "" && s.Password == "" {
+		return true
+	}
+	return slices.Contains(words, "=name="+s.Username) && slices.Contains(words, "=password="+s.Password)
+}
+
+func reply(w proto.Writer, tag string, cmd *mikrotik.FixtureCommand) {
+	if cmd.Trap != "" {
+		writeTrap(w, tag, cmd.Trap)
+		return
+	}
+	for _, re := range cmd.Replies {
+		writeSentence(w, "!re", tag, re)
+	}
+	writeDone(w, tag, cmd.Done)
+}
+
+func writeTrap(w proto.Writer, tag, message string) {
+	writeSentence(w, "!trap", tag, map[string]string{"message": message})
+	writeDone(w, tag, nil)
+}
+
+func writeDone(w proto.Writer, tag string, attrs map[string]string) {
+	writeSentence(w, "!done", tag, attrs)
+}
+
+func writeSentence(w proto.Writer, word, tag string, attrs map[string]string) {
+	w.BeginSentence()
+	w.WriteWord(word)
+	if tag != "" {
+		w.WriteWord(".tag=" + tag)
+	}
+	for _, key := range slices.Sorted(maps.Keys(attrs)) {
+		w.WriteWord(fmt.Sprintf("=%s=%s", key, attrs[key]))
+	}
+	if err := w.EndSentence(); err != nil && !errors.Is(err, net.ErrClosed) {
+		log.Printf("fakeapi: writing sentence: %v", err)
+	}
+}
+
+// readSentence reads the words of a sentence up to the terminating empty word.
+// Unlike proto.Reader it accepts any word, as clients may send bare words such
+// as "without-paging" or query words.
+func readSentence(r *bufio.Reader) ([]string, error) {
+	var words []string
+	for {
+		length, err := readLength(r)
+		if err != nil {
+			return nil, err
+		}
+		if length == 0 {
+			return words, nil
+		}
+		b := make([]byte, length)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		words = append(words, string(b))
+	}
+}
+
+// readLength decodes the variable-length prefix of a word.
+func readLength(r *bufio.Reader) (int, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	var extra int
+	var length int
+	switch {
+	case first&0x80 == 0x00:
+		return int(first), nil
+	case first&0xC0 == 0x80:
+		extra, length = 1, int(first&^0xC0)
+	case first&0xE0 == 0xC0:
+		extra, length = 2, int(first&^0xE0)
+	case first&0xF0 == 0xE0:
+		extra, length = 3, int(first&^0xF0)
+	default:
+		extra, length = 4, 0
+	}
+	for range extra {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+	return length, nil
+}
@@ -0,0 +1,93 @@
+package mikrotik
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// FirewallTables are the firewall tables read by GetFirewallRules.
+var FirewallTables = []string{"filter", "nat", "mangle", "raw"}
+
+// FirewallRule is a firewall rule with its counters.
+type FirewallRule struct {
+	// Family is ipv4 for /ip/firewall and ipv6 for /ipv6/firewall.
+	Family  string
+	Table   string
+	ID      string
+	Key     string
+	Chain   string
+	Action  string
+	Comment string
+	Dynamic bool
+	Bytes   uint64
+	Packets uint64
+}
+
+// firewallKeyIgnored are the attributes left out of the key of a rule because
+// they change without the rule matching different traffic.
+var firewallKeyIgnored = map[string]bool{
+	".id": true, ".nextid": true, "bytes": true, "packets": true, "comment": true,
+	"disabled": true, "dynamic": true, "invalid": true,
+}
+
+// GetFirewallRules fetches the rules and counters of the IPv4 and IPv6
+// filter, nat, mangle and raw tables. Tables missing on the router, such as
+// IPv6 NAT on RouterOS 6, are skipped.
+func (c *Client) GetFirewallRules(ctx context.Context) ([]FirewallRule, error) {
+	var rules []FirewallRule
+	for _, family := range []struct{ name, menu string }{{"ipv4", "/ip/firewall/"}, {"ipv6", "/ipv6/firewall/"}} {
+		for _, table := range FirewallTables {
+			reply, err := c.Run(ctx, family.menu+table+"/print", "stats", "without-paging")
+			if err != nil {
+				if strings.Contains(err.Error(), "no such command") {
+					log.Printf("No %s%s menu on %s. Skipping its firewall metrics.", family.menu, table, c.Address)
+					continue
+				}
+				return nil, fmt.Errorf("failed to get %s%s rules: %w", family.menu, table, err)
+			}
+
+			keys := make(map[string]int)
+			for _, re := range reply.Re {
+				rule := FirewallRule{
+					Family:  family.name,
+					Table:   table,
+					ID:      re.Map[".id"],
+					Key:     firewallRuleKey(re.Map),
+					Chain:   re.Map["chain"],
+					Action:  re.Map["action"],
+					Comment: re.Map["comment"],
+					Dynamic: parseBool(re.Map["dynamic"]),
+					Bytes:   parseUint(re.Map["bytes"]),
+					Packets: parseUint(re.Map["packets"]),
+				}
+				// Identical rules get the same key; number the repetitions in
+				// rule order to keep the series apart.
+				keys[rule.Key]++
+				if n := keys[rule.Key]; n > 1 {
+					rule.Key = fmt.Sprintf("%s-%d", rule.Key, n)
+				}
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// firewallRuleKey returns a short hash of the matchers and action of a rule.
+// Unlike the .id, it stays the same when rules are added, removed or moved,
+// and identifies rules without a comment.
+func firewallRuleKey(attrs map[string]string) string {
+	h := fnv.New64a()
+	for _, key := range slices.Sorted(maps.Keys(attrs)) {
+		if firewallKeyIgnored[key] {
+			continue
+		}
+		fmt.Fprintf(h, "%s=%s\x00", key, attrs[key])
+	}
+	return fmt.Sprintf("%016x", h.Sum64())[:8]
+}
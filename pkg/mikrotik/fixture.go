@@ -0,0 +1,80 @@
+package mikrotik
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// Fixture is a scripted set of replies to RouterOS API commands. Fixtures are
+// served by the fake API server in package fakeapi and describe the reply
+// shapes of real RouterOS versions.
+type Fixture struct {
+	Description string           `yaml:"description,omitempty"`
+	Commands    []FixtureCommand `yaml:"commands"`
+}
+
+// FixtureCommand is the scripted reply to a command.
+type FixtureCommand struct {
+	// Command is the menu path of the command, e.g. /interface/print.
+	Command string `yaml:"command"`
+	// Args are words that must all be part of the command for this entry to
+	// match, e.g. "stats" or "=.proplist=name,type". Among the matching
+	// entries, the one with the most arguments wins.
+	Args []string `yaml:"args,omitempty"`
+	// Replies are sent as !re sentences.
+	Replies []map[string]string `yaml:"replies,omitempty"`
+	// Done holds the attributes of the !done sentence, e.g. ret for count-only prints.
+	Done map[string]string `yaml:"done,omitempty"`
+	// Trap, if set, is sent as the message of a !trap sentence instead of the replies.
+	Trap string `yaml:"trap,omitempty"`
+	// Delay postpones the reply, e.g. to simulate a slow router.
+	Delay time.Duration `yaml:"delay,omitempty"`
+}
+
+// LoadFixture reads a fixture from a YAML file.
+func LoadFixture(path string) (*Fixture, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %w", path, err)
+	}
+	f := &Fixture{}
+	if err := yaml.UnmarshalStrict(content, f); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Lookup returns the entry answering the command given as words. Tags added
+// by the asynchronous mode of the API are ignored.
+func (f *Fixture) Lookup(words []string) (*FixtureCommand, bool) {
+	if len(words) == 0 {
+		return nil, false
+	}
+	args := slices.DeleteFunc(slices.Clone(words[1:]), func(w string) bool {
+		return strings.HasPrefix(w, ".tag=")
+	})
+
+	var best *FixtureCommand
+	for i := range f.Commands {
+		cmd := &f.Commands[i]
+		if cmd.Command != words[0] {
+			continue
+		}
+		matches := true
+		for _, arg := range cmd.Args {
+			if !slices.Contains(args, arg) {
+				matches = false
+				break
+			}
+		}
+		if matches && (best == nil || len(cmd.Args) > len(best.Args)) {
+			best = cmd
+		}
+	}
+	return best, best != nil
+}
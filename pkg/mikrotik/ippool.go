@@ -0,0 +1,181 @@
+package mikrotik
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// IPPool is an address pool from /ip/pool or a prefix pool from /ipv6/pool.
+type IPPool struct {
+	// Family is ipv4 for /ip/pool and ipv6 for /ipv6/pool.
+	Family   string
+	Name     string
+	NextPool string
+	// Size and Used count addresses in IPv4 pools and delegated prefixes in
+	// IPv6 pools.
+	Size uint64
+	Used uint64
+}
+
+// GetIPPools fetches the IPv4 and IPv6 pools with their size and the number
+// of used entries, counted in /ip/pool/used and /ipv6/pool/used with
+// count-only queries. Pools whose ranges cannot be parsed or whose used
+// entries cannot be counted are logged and left out.
+func (c *Client) GetIPPools(ctx context.Context) ([]IPPool, error) {
+	pools, err := c.getIPPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ipv6Pools, err := c.getIPv6Pools(ctx)
+	if err != nil {
+		if !strings.Contains(err.Error(), "no such command") {
+			return nil, err
+		}
+		log.Printf("No /ipv6/pool menu on %s. Skipping IPv6 pool metrics.", c.Address)
+	}
+	pools = append(pools, ipv6Pools...)
+
+	counted := pools[:0]
+	for _, pool := range pools {
+		menu := "/ip/pool/used/print"
+		if pool.Family == "ipv6" {
+			menu = "/ipv6/pool/used/print"
+		}
+		if pool.Used, err = c.count(ctx, menu, "?pool="+pool.Name); err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to count used entries of %s pool %s: %w", pool.Family, pool.Name, err)
+			}
+			log.Printf("Warning: Could not count used entries of %s pool '%s' on %s: %v", pool.Family, pool.Name, c.Address, err)
+			continue
+		}
+		counted = append(counted, pool)
+	}
+	return counted, nil
+}
+
+// getIPPools returns the pools in /ip/pool without their usage. Pools with
+// invalid ranges are logged and skipped.
+func (c *Client) getIPPools(ctx context.Context) ([]IPPool, error) {
+	reply, err := c.Run(ctx, "/ip/pool/print", "without-paging", "=.proplist=name,ranges,next-pool")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IP pools: %w", err)
+	}
+	pools := make([]IPPool, 0, len(reply.Re))
+	for _, re := range reply.Re {
+		size, err := parsePoolRanges(re.Map["ranges"])
+		if err != nil {
+			log.Printf("Warning: Skipping IP pool '%s' on %s with invalid ranges: %v", re.Map["name"], c.Address, err)
+			continue
+		}
+		pools = append(pools, IPPool{
+			Family:   "ipv4",
+			Name:     re.Map["name"],
+			NextPool: poolName(re.Map["next-pool"]),
+			Size:     size,
+		})
+	}
+	return pools, nil
+}
+
+// getIPv6Pools returns the pools in /ipv6/pool without their usage. Pools
+// with an invalid prefix are logged and skipped.
+func (c *Client) getIPv6Pools(ctx context.Context) ([]IPPool, error) {
+	reply, err := c.Run(ctx, "/ipv6/pool/print", "without-paging", "=.proplist=name,prefix,prefix-length")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IPv6 pools: %w", err)
+	}
+	pools := make([]IPPool, 0, len(reply.Re))
+	for _, re := range reply.Re {
+		size, err := parsePrefixPool(re.Map["prefix"], re.Map["prefix-length"])
+		if err != nil {
+			log.Printf("Warning: Skipping IPv6 pool '%s' on %s with invalid prefix: %v", re.Map["name"], c.Address, err)
+			continue
+		}
+		pools = append(pools, IPPool{
+			Family: "ipv6",
+			Name:   re.Map["name"],
+			Size:   size,
+		})
+	}
+	return pools, nil
+}
+
+// poolName returns the name of a pool reference, which is "none" if unset.
+func poolName(name string) string {
+	if name == "none" {
+		return ""
+	}
+	return name
+}
+
+// parsePrefixPool returns the number of prefixes of length prefixLength that
+// an IPv6 prefix pool hands out of prefix, e.g. 256 /56 prefixes of a /48.
+func parsePrefixPool(prefix, prefixLength string) (uint64, error) {
+	p, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return 0, fmt.Errorf("invalid prefix %q: %w", prefix, err)
+	}
+	length, err := strconv.Atoi(prefixLength)
+	if err != nil || length < p.Bits() || length > p.Addr().BitLen() {
+		return 0, fmt.Errorf("invalid prefix length %q for prefix %s", prefixLength, prefix)
+	}
+	if length-p.Bits() >= 64 {
+		return ^uint64(0), nil
+	}
+	return 1 << (length - p.Bits()), nil
+}
+
+// parsePoolRanges returns the number of addresses in the ranges of an IP
+// pool, a comma separated list of address ranges ("10.0.0.10-10.0.0.99"),
+// prefixes ("10.0.1.0/24") and single addresses. Sizes that do not fit in a
+// uint64, which only IPv6 ranges reach, are capped.
+func parsePoolRanges(ranges string) (uint64, error) {
+	total := new(big.Int)
+	for _, r := range strings.Split(ranges, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		size, err := parsePoolRange(r)
+		if err != nil {
+			return 0, err
+		}
+		total.Add(total, size)
+	}
+	if !total.IsUint64() {
+		return ^uint64(0), nil
+	}
+	return total.Uint64(), nil
+}
+
+func parsePoolRange(r string) (*big.Int, error) {
+	if strings.Contains(r, "/") {
+		prefix, err := netip.ParsePrefix(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prefix %q: %w", r, err)
+		}
+		return new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefix.Bits())), nil
+	}
+
+	first, last, isRange := strings.Cut(r, "-")
+	from, err := netip.ParseAddr(strings.TrimSpace(first))
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", first, err)
+	}
+	to := from
+	if isRange {
+		if to, err = netip.ParseAddr(strings.TrimSpace(last)); err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", last, err)
+		}
+	}
+	if from.BitLen() != to.BitLen() || to.Less(from) {
+		return nil, fmt.Errorf("invalid range %q", r)
+	}
+	size := new(big.Int).Sub(new(big.Int).SetBytes(to.AsSlice()), new(big.Int).SetBytes(from.AsSlice()))
+	return size.Add(size, big.NewInt(1)), nil
+}
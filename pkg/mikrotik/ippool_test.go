@@ -0,0 +1,51 @@
+package mikrotik
+
+import "testing"
+
+func TestParsePoolRanges(t *testing.T) {
+	for in, want := range map[string]uint64{
+		"192.168.88.10-192.168.88.254":            245,
+		"10.0.0.1":                                1,
+		"10.0.1.0/24":                             256,
+		"100.64.0.2-100.64.3.254,100.64.8.0/22":   1021 + 1024,
+		"10.0.0.1-10.0.0.10, 10.0.0.20-10.0.0.29": 20,
+		"2001:db8::1-2001:db8::ff":                255,
+		"2001:db8::/64":                           ^uint64(0),
+		"2001:db8::/65":                           1 << 63,
+		"":                                        0,
+	} {
+		got, err := parsePoolRanges(in)
+		if err != nil || got != want {
+			t.Errorf("parsePoolRanges(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"10.0.0.10-10.0.0.1", "10.0.0.1-2001:db8::1", "10.0.0", "10.0.0.0/33", "a-b"} {
+		if _, err := parsePoolRanges(in); err == nil {
+			t.Errorf("parsePoolRanges(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestParsePrefixPool(t *testing.T) {
+	for _, tc := range []struct {
+		prefix, length string
+		want           uint64
+	}{
+		{"2001:db8::/48", "56", 256},
+		{"2001:db8::/48", "64", 65536},
+		{"2001:db8:1::/64", "64", 1},
+		{"2001:db8::/32", "128", ^uint64(0)},
+	} {
+		got, err := parsePrefixPool(tc.prefix, tc.length)
+		if err != nil || got != tc.want {
+			t.Errorf("parsePrefixPool(%q, %q) = %d, %v; want %d", tc.prefix, tc.length, got, err, tc.want)
+		}
+	}
+
+	for _, tc := range [][2]string{{"2001:db8::/48", "40"}, {"2001:db8::/48", "129"}, {"2001:db8::/48", ""}, {"2001:db8::", "64"}} {
+		if _, err := parsePrefixPool(tc[0], tc[1]); err == nil {
+			t.Errorf("parsePrefixPool(%q, %q) succeeded, want an error", tc[0], tc[1])
+		}
+	}
+}
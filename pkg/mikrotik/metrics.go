@@ -0,0 +1,34 @@
+package mikrotik
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	commandsTimedOut = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "ros_exporter",
+			Name:      "api_commands_timed_out_total",
+			Help:      "Total number of RouterOS API commands aborted because their deadline was exceeded.",
+		},
+		[]string{"command"},
+	)
+	commandsCancelled = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "ros_exporter",
+			Name:      "api_commands_cancelled_total",
+			Help:      "Total number of RouterOS API commands aborted because the scrape was cancelled.",
+		},
+		[]string{"command"},
+	)
+	dialFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "ros_exporter",
+			Name:      "api_dial_failures_total",
+			Help:      "Total number of failed attempts to connect and log in to a router.",
+		},
+	)
+)
+
+// Collectors returns the exporter self-metrics maintained by this package.
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{commandsTimedOut, commandsCancelled, dialFailures}
+}
@@ -0,0 +1,154 @@
+package mikrotik
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// OSPFNeighbor is an OSPF adjacency from /routing/ospf/neighbor.
+type OSPFNeighbor struct {
+	Instance     string
+	Area         string
+	Interface    string
+	Address      string
+	RouterID     string
+	State        string
+	Priority     string
+	DR           string
+	BDR          string
+	StateChanges uint64
+	Adjacency    time.Duration
+}
+
+// OSPFInstance is an OSPF instance with the number of LSAs in its database by
+// LSA type.
+type OSPFInstance struct {
+	Name     string
+	RouterID string
+	Version  string
+	Disabled bool
+	LSAs     map[string]uint64
+}
+
+// ospfLSATypes returns the LSA types counted by GetOSPFInstances for an
+// instance of the given OSPF version. RouterOS 6 names external LSAs
+// as-external and has no OSPFv3 instances in /routing/ospf.
+func ospfLSATypes(v6 bool, version string) []string {
+	switch {
+	case v6:
+		return []string{"router", "network", "summary-network", "summary-asbr", "as-external", "nssa-external"}
+	case version == "3":
+		return []string{"router", "network", "inter-area-prefix", "inter-area-router", "external", "nssa-external", "link", "intra-area-prefix"}
+	}
+	return []string{"router", "network", "summary-network", "summary-asbr", "external", "nssa-external"}
+}
+
+// GetOSPFNeighbors fetches the OSPF neighbors of all instances.
+func (c *Client) GetOSPFNeighbors(ctx context.Context) ([]OSPFNeighbor, error) {
+	if !c.capabilities(ctx).HasOSPF() {
+		log.Printf("Routing package is disabled on %s. Skipping OSPF metrics.", c.Address)
+		return []OSPFNeighbor{}, nil
+	}
+
+	reply, err := c.Run(ctx, "/routing/ospf/neighbor/print", "without-paging")
+	if err != nil {
+		if strings.Contains(err.Error(), "no such command") || strings.Contains(err.Error(), "disabled") {
+			log.Printf("OSPF feature might be disabled on %s. Skipping OSPF neighbor metrics.", c.Address)
+			return []OSPFNeighbor{}, nil
+		}
+		return nil, fmt.Errorf("failed to get OSPF neighbors: %w", err)
+	}
+
+	neighbors := make([]OSPFNeighbor, 0, len(reply.Re))
+	for _, re := range reply.Re {
+		neighbor := OSPFNeighbor{
+			Instance:     re.Map["instance"],
+			Area:         re.Map["area"],
+			Interface:    re.Map["interface"],
+			Address:      re.Map["address"],
+			RouterID:     re.Map["router-id"],
+			State:        re.Map["state"],
+			Priority:     re.Map["priority"],
+			DR:           re.Map["dr"],
+			BDR:          re.Map["bdr"],
+			StateChanges: parseUint(re.Map["state-changes"]),
+		}
+		// RouterOS 6 names the designated routers differently.
+		if neighbor.DR == "" {
+			neighbor.DR = re.Map["dr-address"]
+		}
+		if neighbor.BDR == "" {
+			neighbor.BDR = re.Map["backup-dr-address"]
+		}
+		if neighbor.RouterID == "" && neighbor.Address == "" {
+			log.Printf("Warning: Skipping OSPF neighbor without router-id and address: %v", re.Map)
+			continue
+		}
+		if adjacency := re.Map["adjacency"]; adjacency != "" {
+			neighbor.Adjacency, err = parseMikrotikDuration(adjacency)
+			if err != nil {
+				log.Printf("Warning: Could not parse OSPF adjacency '%s' for neighbor '%s': %v", adjacency, neighbor.RouterID, err)
+			}
+		}
+		neighbors = append(neighbors, neighbor)
+	}
+	return neighbors, nil
+}
+
+// GetOSPFInstances fetches the OSPF instances and counts the LSAs in their
+// link-state databases per LSA type with count-only queries, so the cost does
+// not grow with the size of the databases. Disabled instances have no LSAs.
+func (c *Client) GetOSPFInstances(ctx context.Context) ([]OSPFInstance, error) {
+	caps := c.capabilities(ctx)
+	if !caps.HasOSPF() {
+		return []OSPFInstance{}, nil
+	}
+
+	reply, err := c.Run(ctx, "/routing/ospf/instance/print", "without-paging")
+	if err != nil {
+		if strings.Contains(err.Error(), "no such command") || strings.Contains(err.Error(), "disabled") {
+			log.Printf("OSPF feature might be disabled on %s. Skipping OSPF instance metrics.", c.Address)
+			return []OSPFInstance{}, nil
+		}
+		return nil, fmt.Errorf("failed to get OSPF instances: %w", err)
+	}
+	if len(reply.Re) == 0 {
+		return []OSPFInstance{}, nil
+	}
+
+	v6 := caps.Major != 0 && !caps.IsV7()
+
+	instances := make([]OSPFInstance, 0, len(reply.Re))
+	for _, re := range reply.Re {
+		name := re.Map["name"]
+		if name == "" {
+			log.Printf("Warning: Skipping OSPF instance with empty name: %v", re.Map)
+			continue
+		}
+		routerID := re.Map["router-id"]
+		if routerID == "" || routerID == "0.0.0.0" {
+			routerID = re.Map["effective-router-id"]
+		}
+		instance := OSPFInstance{
+			Name:     name,
+			RouterID: routerID,
+			Version:  re.Map["version"],
+			Disabled: parseBool(re.Map["disabled"]),
+			LSAs:     make(map[string]uint64),
+		}
+		if !instance.Disabled {
+			for _, lsaType := range ospfLSATypes(v6, instance.Version) {
+				n, err := c.count(ctx, "/routing/ospf/lsa/print", "?instance="+name, "?type="+lsaType)
+				if err != nil {
+					return nil, fmt.Errorf("failed to count OSPF %s LSAs of instance %s: %w", lsaType, name, err)
+				}
+				instance.LSAs[lsaType] = n
+			}
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
@@ -0,0 +1,272 @@
+package mikrotik
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolKey identifies clients that can share an authenticated session.
+type poolKey struct {
+	address        string
+	username       string
+	password       string
+	tls            TLSConfig
+	maxConcurrency int
+	backend        string
+}
+
+type idleClient struct {
+	client   *Client
+	lastUsed time.Time
+}
+
+// Pool keeps authenticated API sessions alive between scrapes. Clients are
+// taken from the pool with Get and returned with Put; idle sessions are
+// health-checked periodically and closed after the idle timeout.
+type Pool struct {
+	idleTimeout      time.Duration
+	maxIdlePerTarget int
+
+	mu    sync.Mutex
+	idle  map[poolKey][]idleClient
+	inUse int
+
+	stop chan struct{}
+	done chan struct{}
+
+	sizeDesc *prometheus.Desc
+	idleDesc *prometheus.Desc
+}
+
+// NewPool returns a pool that keeps up to maxIdlePerTarget idle sessions per
+// target and closes sessions that have been idle for longer than idleTimeout.
+func NewPool(idleTimeout time.Duration, maxIdlePerTarget int) *Pool {
+	p := &Pool{
+		idleTimeout:      idleTimeout,
+		maxIdlePerTarget: maxIdlePerTarget,
+		idle:             make(map[poolKey][]idleClient),
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+		sizeDesc: prometheus.NewDesc(
+			"ros_exporter_pool_connections",
+			"Number of API sessions held by the connection pool, idle or in use.",
+			nil, nil,
+		),
+		idleDesc: prometheus.NewDesc(
+			"ros_exporter_pool_idle_connections",
+			"Number of idle API sessions in the connection pool.",
+			nil, nil,
+		),
+	}
+	go p.maintain()
+	return p
+}
+
+func keyOf(c *Client) poolKey {
+	return poolKey{
+		address:        c.Address,
+		username:       c.Username,
+		password:       c.Password,
+		tls:            c.TLS,
+		maxConcurrency: c.MaxConcurrency,
+		backend:        c.Backend,
+	}
+}
+
+// Get returns an idle client with the same target, credentials and transport
+// settings as template, or template itself if there is none. The client must
+// be handed back with Put once the scrape is done.
+func (p *Pool) Get(template *Client) *Client {
+	key := keyOf(template)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.inUse++
+	clients := p.idle[key]
+	if len(clients) == 0 {
+		return template
+	}
+	ic := clients[len(clients)-1]
+	p.setIdle(key, clients[:len(clients)-1])
+
+	ic.client.Timeout = template.Timeout
+	ic.client.Fixture = template.Fixture
+	log.Printf("Reusing pooled connection to MikroTik router %s", ic.client.Address)
+	return ic.client
+}
+
+// Put returns a client obtained from Get to the pool. Clients without an open
+// connection, or exceeding the idle limit, are closed.
+func (p *Pool) Put(c *Client) {
+	key := keyOf(c)
+
+	p.mu.Lock()
+	p.inUse--
+	if !c.Connected() || len(p.idle[key]) >= p.maxIdlePerTarget || p.isStopped() {
+		p.mu.Unlock()
+		c.Close()
+		return
+	}
+	p.idle[key] = append(p.idle[key], idleClient{client: c, lastUsed: time.Now()})
+	p.mu.Unlock()
+}
+
+// Close closes all idle sessions and stops the maintenance loop. Clients that
+// are in use are closed when they are returned.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.isStopped() {
+		p.mu.Unlock()
+		return
+	}
+	close(p.stop)
+	idle := p.idle
+	p.idle = make(map[poolKey][]idleClient)
+	p.mu.Unlock()
+
+	<-p.done
+	for _, clients := range idle {
+		for _, ic := range clients {
+			ic.client.Close()
+		}
+	}
+}
+
+func (p *Pool) isStopped() bool {
+	select {
+	case <-p.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Pool) setIdle(key poolKey, clients []idleClient) {
+	if len(clients) == 0 {
+		delete(p.idle, key)
+		return
+	}
+	p.idle[key] = clients
+}
+
+// maintain periodically evicts expired sessions and health-checks the rest.
+func (p *Pool) maintain() {
+	defer close(p.done)
+
+	interval := min(max(p.idleTimeout/2, time.Second), 30*time.Second)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.sweep()
+		}
+	}
+}
+
+func (p *Pool) sweep() {
+	now := time.Now()
+
+	// Expired sessions are taken out of the pool at once, the others are
+	// health-checked below.
+	var expired, check []*Client
+	p.mu.Lock()
+	for key, clients := range p.idle {
+		kept := clients[:0]
+		for _, ic := range clients {
+			if now.Sub(ic.lastUsed) > p.idleTimeout {
+				expired = append(expired, ic.client)
+				continue
+			}
+			kept = append(kept, ic)
+			check = append(check, ic.client)
+		}
+		p.setIdle(key, kept)
+	}
+	p.mu.Unlock()
+
+	for _, c := range expired {
+		log.Printf("Closing idle pooled connection to MikroTik router %s", c.Address)
+		c.Close()
+	}
+
+	// Only the session being checked is taken out of the pool, so that the
+	// others can still be handed out to scrapes meanwhile.
+	for _, c := range check {
+		ic, ok := p.take(c)
+		if !ok {
+			continue
+		}
+		if !p.healthy(c) {
+			log.Printf("Closing unhealthy pooled connection to MikroTik router %s", c.Address)
+			c.Close()
+			continue
+		}
+
+		key := keyOf(c)
+		p.mu.Lock()
+		kept := len(p.idle[key]) < p.maxIdlePerTarget && !p.isStopped()
+		if kept {
+			p.idle[key] = append(p.idle[key], ic)
+		}
+		p.mu.Unlock()
+		if !kept {
+			c.Close()
+		}
+	}
+}
+
+// take removes an idle session from the pool. ok is false if the session has
+// been handed out by Get in the meantime.
+func (p *Pool) take(c *Client) (ic idleClient, ok bool) {
+	key := keyOf(c)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	clients := p.idle[key]
+	i := slices.IndexFunc(clients, func(ic idleClient) bool { return ic.client == c })
+	if i < 0 {
+		return idleClient{}, false
+	}
+	ic = clients[i]
+	p.setIdle(key, slices.Delete(clients, i, i+1))
+	return ic, true
+}
+
+// healthy runs a cheap command on the open connection of an idle session to
+// check that it still works, without reconnecting a dropped one.
+func (p *Pool) healthy(c *Client) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+	return c.ping(ctx) == nil && c.Connected()
+}
+
+// Describe implements prometheus.Collector.
+func (p *Pool) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p.sizeDesc
+	ch <- p.idleDesc
+}
+
+// Collect implements prometheus.Collector.
+func (p *Pool) Collect(ch chan<- prometheus.Metric) {
+	p.mu.Lock()
+	idle := 0
+	for _, clients := range p.idle {
+		idle += len(clients)
+	}
+	inUse := p.inUse
+	p.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(p.sizeDesc, prometheus.GaugeValue, float64(idle+inUse))
+	ch <- prometheus.MustNewConstMetric(p.idleDesc, prometheus.GaugeValue, float64(idle))
+}
@@ -0,0 +1,167 @@
+package mikrotik_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/taihen/ros-exporter/pkg/mikrotik"
+	"github.com/taihen/ros-exporter/pkg/mikrotik/fakeapi"
+)
+
+func TestPoolEvictsDroppedSessions(t *testing.T) {
+	srv, err := fakeapi.NewServer(&mikrotik.Fixture{Commands: []mikrotik.FixtureCommand{
+		{Command: "/system/identity/print", Replies: []map[string]string{{"name": "router"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	// The health check runs every second with this idle timeout.
+	pool := mikrotik.NewPool(2*time.Second, 2)
+	defer pool.Close()
+
+	template := mikrotik.NewClient(srv.Addr(), "prometheus", "secret", 5*time.Second)
+	client := pool.Get(template)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	pool.Put(client)
+
+	srv.DropConnections()
+	time.Sleep(1500 * time.Millisecond)
+
+	// The dropped session is closed by the health check instead of being
+	// reconnected, so the next scrape starts with a new client.
+	if got := pool.Get(template); got != template {
+		t.Error("pool handed out the dropped session")
+	}
+	if got := srv.Logins(); got != 1 {
+		t.Errorf("router saw %d logins, want 1", got)
+	}
+}
+
+// poolGauges gathers the metrics of pool by name.
+func poolGauges(t *testing.T, pool *mikrotik.Pool) map[string]float64 {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(pool)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gauges := make(map[string]float64)
+	for _, mf := range families {
+		gauges[mf.GetName()] = mf.GetMetric()[0].GetGauge().GetValue()
+	}
+	return gauges
+}
+
+func newPoolServer(t *testing.T) *fakeapi.Server {
+	t.Helper()
+	srv, err := fakeapi.NewServer(&mikrotik.Fixture{Commands: []mikrotik.FixtureCommand{
+		{Command: "/system/identity/print", Replies: []map[string]string{{"name": "router"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return srv
+}
+
+func TestPoolReusesSessions(t *testing.T) {
+	srv := newPoolServer(t)
+	pool := mikrotik.NewPool(time.Minute, 2)
+	defer pool.Close()
+
+	template := mikrotik.NewClient(srv.Addr(), "prometheus", "secret", 5*time.Second)
+	first := pool.Get(template)
+	if _, err := first.Run(context.Background(), "/system/identity/print"); err != nil {
+		t.Fatal(err)
+	}
+	if got := poolGauges(t, pool); got["ros_exporter_pool_connections"] != 1 || got["ros_exporter_pool_idle_connections"] != 0 {
+		t.Errorf("pool metrics during the scrape = %v, want 1 connection and 0 idle", got)
+	}
+	pool.Put(first)
+	if got := poolGauges(t, pool); got["ros_exporter_pool_connections"] != 1 || got["ros_exporter_pool_idle_connections"] != 1 {
+		t.Errorf("pool metrics after the scrape = %v, want 1 connection and 1 idle", got)
+	}
+
+	// A new template for the same target, as built by the next scrape.
+	second := pool.Get(mikrotik.NewClient(srv.Addr(), "prometheus", "secret", 5*time.Second))
+	if second != first {
+		t.Error("second scrape did not get the pooled session")
+	}
+	if _, err := second.Run(context.Background(), "/system/identity/print"); err != nil {
+		t.Fatal(err)
+	}
+	pool.Put(second)
+	if got := srv.Logins(); got != 1 {
+		t.Errorf("router saw %d logins, want 1", got)
+	}
+
+	// Other credentials do not share the session.
+	other := mikrotik.NewClient(srv.Addr(), "monitoring", "secret", 5*time.Second)
+	if got := pool.Get(other); got != other {
+		t.Error("pool handed out a session of other credentials")
+	}
+}
+
+func TestPoolClosesIdleSessions(t *testing.T) {
+	srv := newPoolServer(t)
+	// The sessions expire before the first sweep a second later.
+	pool := mikrotik.NewPool(500*time.Millisecond, 2)
+	defer pool.Close()
+
+	template := mikrotik.NewClient(srv.Addr(), "prometheus", "secret", 5*time.Second)
+	client := pool.Get(template)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	pool.Put(client)
+
+	time.Sleep(1500 * time.Millisecond)
+	if client.Connected() {
+		t.Error("idle session was not closed")
+	}
+	if got := poolGauges(t, pool); got["ros_exporter_pool_connections"] != 0 || got["ros_exporter_pool_idle_connections"] != 0 {
+		t.Errorf("pool metrics = %v, want no connections", got)
+	}
+	if got := pool.Get(template); got != template {
+		t.Error("pool handed out the expired session")
+	}
+}
+
+func TestPoolMaxIdlePerTarget(t *testing.T) {
+	srv := newPoolServer(t)
+	pool := mikrotik.NewPool(time.Minute, 1)
+	defer pool.Close()
+
+	// Two concurrent scrapes of the same target open two sessions.
+	var clients []*mikrotik.Client
+	for range 2 {
+		client := pool.Get(mikrotik.NewClient(srv.Addr(), "prometheus", "secret", 5*time.Second))
+		if err := client.Connect(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		clients = append(clients, client)
+	}
+	if got := poolGauges(t, pool); got["ros_exporter_pool_connections"] != 2 {
+		t.Errorf("pool metrics = %v, want 2 connections", got)
+	}
+	for _, client := range clients {
+		pool.Put(client)
+	}
+
+	if !clients[0].Connected() {
+		t.Error("first session was not kept")
+	}
+	if clients[1].Connected() {
+		t.Error("session exceeding the idle limit was not closed")
+	}
+	if got := poolGauges(t, pool); got["ros_exporter_pool_connections"] != 1 || got["ros_exporter_pool_idle_connections"] != 1 {
+		t.Errorf("pool metrics = %v, want 1 idle connection", got)
+	}
+}
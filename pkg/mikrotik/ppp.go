@@ -1,6 +1,7 @@
 package mikrotik
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strconv"
@@ -8,8 +9,13 @@ import (
 )
 
 // GetPPPActiveUsers fetches statistics for all active PPP users.
-func (c *Client) GetPPPActiveUsers() ([]PPPUserStat, error) {
-	reply, err := c.Run("/ppp/active/print", "without-paging")
+func (c *Client) GetPPPActiveUsers(ctx context.Context) ([]PPPUserStat, error) {
+	if !c.capabilities(ctx).HasPPP() {
+		log.Printf("PPP package is disabled on %s. Skipping PPP metrics.", c.Address)
+		return []PPPUserStat{}, nil
+	}
+
+	reply, err := c.Run(ctx, "/ppp/active/print", "without-paging")
 	if err != nil {
 		if strings.Contains(err.Error(), "no such command") || strings.Contains(err.Error(), "disabled") {
 			log.Printf("PPP feature might be disabled on %s. Skipping PPP metrics.", c.Address)
@@ -0,0 +1,271 @@
+package mikrotik
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-routeros/routeros/v3"
+	"go.yaml.in/yaml/v2"
+)
+
+// Redaction selects the data a Recorder replaces with placeholders before a
+// fixture is written. Passwords and other secrets are always redacted.
+type Redaction struct {
+	Serials bool
+	MACs    bool
+	IPs     bool
+}
+
+// ParseRedaction parses a comma separated list of "serials", "macs" and "ips".
+func ParseRedaction(s string) (Redaction, error) {
+	var r Redaction
+	for _, item := range strings.Split(s, ",") {
+		switch strings.TrimSpace(item) {
+		case "":
+		case "serials":
+			r.Serials = true
+		case "macs":
+			r.MACs = true
+		case "ips":
+			r.IPs = true
+		default:
+			return r, fmt.Errorf("unknown redaction %q, must be serials, macs or ips", item)
+		}
+	}
+	return r, nil
+}
+
+// Recorder captures the commands run by a client and their replies, so that
+// they can be saved as a fixture and served by the replay backend or the fake
+// API server.
+type Recorder struct {
+	redaction Redaction
+
+	mu       sync.Mutex
+	commands []FixtureCommand
+	macs     map[string]string
+	serials  map[string]string
+	// ipv4Nets and ipv6Nets map the networks of redacted addresses to their
+	// placeholder networks, see redactIP.
+	ipv4Nets map[uint16]uint16
+	ipv6Nets map[uint32]uint32
+}
+
+// NewRecorder returns a recorder applying the given redaction.
+func NewRecorder(redaction Redaction) *Recorder {
+	return &Recorder{
+		redaction: redaction,
+		macs:      make(map[string]string),
+		serials:   make(map[string]string),
+		ipv4Nets:  make(map[uint16]uint16),
+		ipv6Nets:  make(map[uint32]uint32),
+	}
+}
+
+// record adds a command and its outcome. Errors other than !trap replies,
+// e.g. timeouts, are not part of a fixture and are skipped.
+func (r *Recorder) record(args []string, reply *routeros.Reply, err error) {
+	if len(args) == 0 || args[0] == "/login" {
+		return
+	}
+
+	cmd := FixtureCommand{Command: args[0]}
+	for _, arg := range args[1:] {
+		if !strings.HasPrefix(arg, ".tag=") {
+			cmd.Args = append(cmd.Args, arg)
+		}
+	}
+
+	var deviceErr *routeros.DeviceError
+	switch {
+	case errors.As(err, &deviceErr):
+		cmd.Trap = deviceErr.Sentence.Map["message"]
+	case err != nil:
+		return
+	default:
+		for _, re := range reply.Re {
+			cmd.Replies = append(cmd.Replies, re.Map)
+		}
+		if reply.Done != nil && len(reply.Done.Map) > 0 {
+			cmd.Done = reply.Done.Map
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = slices.DeleteFunc(r.commands, func(c FixtureCommand) bool {
+		return c.Command == cmd.Command && slices.Equal(c.Args, cmd.Args)
+	})
+	r.commands = append(r.commands, r.sanitize(cmd))
+}
+
+// Fixture returns the commands recorded so far.
+func (r *Recorder) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f := &Fixture{Commands: slices.Clone(r.commands)}
+	for _, cmd := range f.Commands {
+		if cmd.Command == "/system/resource/print" && len(cmd.Replies) > 0 {
+			res := cmd.Replies[0]
+			f.Description = strings.TrimSpace(fmt.Sprintf("RouterOS %s %s", res["version"], res["board-name"]))
+		}
+	}
+	return f
+}
+
+// WriteFile saves the recorded commands as a YAML fixture.
+func (r *Recorder) WriteFile(path string) error {
+	content, err := yaml.Marshal(r.Fixture())
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write fixture %s: %w", path, err)
+	}
+	return nil
+}
+
+var (
+	macPattern  = regexp.MustCompile(`(?i)\b[0-9a-f]{2}(?::[0-9a-f]{2}){5}\b`)
+	ipv4Pattern = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}\b`)
+	ipv6Pattern = regexp.MustCompile(`(?i)[0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7}`)
+)
+
+// secretKeys are attributes that are always redacted.
+var secretKeys = []string{"password", "secret", "key", "passphrase", "psk"}
+
+// sanitize replaces secrets and the selected identifying data in a command.
+// The same value is always replaced by the same placeholder, so that
+// references between replies survive.
+func (r *Recorder) sanitize(cmd FixtureCommand) FixtureCommand {
+	for i, arg := range cmd.Args {
+		cmd.Args[i] = r.redactValue(arg)
+	}
+	replies := make([]map[string]string, len(cmd.Replies))
+	for i, re := range cmd.Replies {
+		replies[i] = r.redactMap(re)
+	}
+	cmd.Replies = replies
+	if cmd.Done != nil {
+		cmd.Done = r.redactMap(cmd.Done)
+	}
+	cmd.Trap = r.redactValue(cmd.Trap)
+	return cmd
+}
+
+func (r *Recorder) redactMap(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for key, value := range m {
+		switch {
+		case value == "":
+			out[key] = value
+		case isSecretKey(key):
+			out[key] = "REDACTED"
+		case r.redaction.Serials && strings.Contains(key, "serial"):
+			out[key] = placeholder(r.serials, value, func(n int) string { return fmt.Sprintf("SERIAL%d", n) })
+		default:
+			out[key] = r.redactValue(value)
+		}
+	}
+	return out
+}
+
+func isSecretKey(key string) bool {
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recorder) redactValue(value string) string {
+	if r.redaction.MACs {
+		value = macPattern.ReplaceAllStringFunc(value, func(mac string) string {
+			return placeholder(r.macs, strings.ToUpper(mac), func(n int) string {
+				return fmt.Sprintf("02:00:00:00:%02X:%02X", n>>8&0xff, n&0xff)
+			})
+		})
+	}
+	if r.redaction.IPs {
+		value = ipv4Pattern.ReplaceAllStringFunc(value, r.redactIP)
+		value = ipv6Pattern.ReplaceAllStringFunc(value, r.redactIP)
+	}
+	return value
+}
+
+// redactIP replaces the network of an address, the first 16 bits of IPv4 and
+// the first 32 bits of IPv6 addresses, with a placeholder network and keeps
+// the rest. Addresses of the same network thus keep their order and distance,
+// so that pool ranges and prefixes replay with their size. Placeholder
+// networks are assigned in the order the networks are first seen. Strings
+// that only look like addresses are kept.
+func (r *Recorder) redactIP(s string) string {
+	addr, err := netip.ParseAddr(s)
+	if err != nil || addr.IsUnspecified() || addr.IsLoopback() {
+		return s
+	}
+	if addr.Is4() {
+		a := addr.As4()
+		network := binary.BigEndian.Uint16(a[:2])
+		p, ok := r.ipv4Nets[network]
+		if !ok {
+			p = ipv4Placeholder(len(r.ipv4Nets))
+			r.ipv4Nets[network] = p
+		}
+		binary.BigEndian.PutUint16(a[:2], p)
+		return netip.AddrFrom4(a).String()
+	}
+	a := addr.As16()
+	network := binary.BigEndian.Uint32(a[:4])
+	p, ok := r.ipv6Nets[network]
+	if !ok {
+		p = ipv6Placeholder(len(r.ipv6Nets))
+		r.ipv6Nets[network] = p
+	}
+	binary.BigEndian.PutUint32(a[:4], p)
+	return netip.AddrFrom16(a).WithZone(addr.Zone()).String()
+}
+
+// ipv4Placeholder returns the n-th placeholder /16 network: 10.0.0.0/16,
+// 10.1.0.0/16 and so on through the address space, skipping 127.0.0.0/8 and
+// 0.0.0.0/8.
+func ipv4Placeholder(n int) uint16 {
+	first := 10 + n/256
+	if first >= 127 {
+		first++
+	}
+	if first > 255 {
+		first -= 255
+	}
+	return uint16(first)<<8 | uint16(n%256)
+}
+
+// ipv6Placeholder returns the n-th placeholder /32 network: the documentation
+// prefix 2001:db8::/32 of RFC 3849, then 3fff::/32, 3fff:1::/32 and so on from
+// the documentation prefix 3fff::/20 of RFC 9637.
+func ipv6Placeholder(n int) uint32 {
+	if n == 0 {
+		return 0x20010db8
+	}
+	return 0x3fff0000 + uint32(n-1)
+}
+
+// placeholder returns the placeholder assigned to value, creating a new one
+// with format if needed.
+func placeholder(assigned map[string]string, value string, format func(n int) string) string {
+	if p, ok := assigned[value]; ok {
+		return p
+	}
+	p := format(len(assigned) + 1)
+	assigned[value] = p
+	return p
+}
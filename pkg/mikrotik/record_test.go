@@ -0,0 +1,252 @@
+package mikrotik_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taihen/ros-exporter/pkg/mikrotik"
+	"github.com/taihen/ros-exporter/pkg/mikrotik/fakeapi"
+)
+
+var liveFixture = &mikrotik.Fixture{Commands: []mikrotik.FixtureCommand{
+	{Command: "/system/resource/print", Replies: []map[string]string{{
+		"version": "7.12.1 (stable)", "board-name": "hAP ax2", "uptime": "1d2h",
+		"free-memory": "100", "total-memory": "200", "cpu-load": "5",
+		"free-hdd-space": "10", "total-hdd-space": "20",
+	}}},
+	{Command: "/system/routerboard/print", Replies: []map[string]string{{
+		"model": "C52iG-5HaxD2HaxD", "serial-number": "HEX123456", "current-firmware": "7.12.1",
+	}}},
+	{Command: "/interface/print", Args: []string{"=.proplist=name,type"}, Replies: []map[string]string{
+		{"name": "ether1", "type": "ether"},
+	}},
+	{Command: "/interface/print", Args: []string{"detail"}, Replies: []map[string]string{
+		{"name": "ether1", "type": "ether", "mac-address": "48:a9:8a:12:34:56", "comment": "uplink to 203.0.113.7", "running": "true"},
+	}},
+	{Command: "/interface/print", Args: []string{"stats"}, Replies: []map[string]string{
+		{"name": "ether1", "rx-byte": "1000", "tx-byte": "2000"},
+	}},
+	{Command: "/ppp/active/print", Replies: []map[string]string{
+		{"name": "alice", "service": "pppoe", "caller-id": "48:A9:8A:12:34:56", "address": "203.0.113.7", "uptime": "1h", "password": "hunter2"},
+	}},
+}}
+
+func TestRecordAndReplay(t *testing.T) {
+	srv, err := fakeapi.NewServer(liveFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	ctx := context.Background()
+	live := mikrotik.NewClient(srv.Addr(), "prometheus", "secret", 5*time.Second)
+	live.Recorder = mikrotik.NewRecorder(mikrotik.Redaction{Serials: true, MACs: true, IPs: true})
+	defer live.Close()
+
+	liveRes, err := live.GetSystemResources(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	liveIfaces, err := live.GetInterfaceStats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := live.GetRouterboard(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := live.GetPPPActiveUsers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := live.GetBGPPeerStats(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.yml")
+	if err := live.Recorder.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := mikrotik.LoadFixture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fixture.Description != "RouterOS 7.12.1 (stable) hAP ax2" {
+		t.Errorf("description = %q", fixture.Description)
+	}
+
+	content := fixtureText(fixture)
+	for _, secret := range []string{"HEX123456", "48:A9:8A:12:34:56", "48:a9:8a:12:34:56", "203.0.113.7", "hunter2"} {
+		if strings.Contains(content, secret) {
+			t.Errorf("recorded fixture contains %q", secret)
+		}
+	}
+
+	// Missing menus are recorded as !trap replies.
+	if cmd, ok := fixture.Lookup([]string{"/system/package/print", "without-paging", "=.proplist=name,disabled"}); !ok || cmd.Trap != fakeapi.NoSuchCommand {
+		t.Errorf("/system/package/print not recorded as trap: %+v", cmd)
+	}
+
+	replay := mikrotik.NewClient("replay", "", "", 5*time.Second)
+	replay.Backend = mikrotik.BackendReplay
+	replay.Fixture = fixture
+
+	replayRes, err := replay.GetSystemResources(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(liveRes, replayRes) {
+		t.Errorf("replayed system resources differ:\n live: %+v\nreplay: %+v", liveRes, replayRes)
+	}
+
+	replayIfaces, err := replay.GetInterfaceStats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayIfaces) != 1 || replayIfaces[0].RxBytes != liveIfaces[0].RxBytes || replayIfaces[0].MACAddress != "02:00:00:00:00:01" {
+		t.Errorf("unexpected replayed interfaces: %+v", replayIfaces)
+	}
+	if replayIfaces[0].Comment != "uplink to 10.0.113.7" {
+		t.Errorf("comment = %q, want the redacted address", replayIfaces[0].Comment)
+	}
+
+	users, err := replay.GetPPPActiveUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The same address is replaced by the same placeholder everywhere.
+	if len(users) != 1 || users[0].CallerID != "02:00:00:00:00:01" || users[0].Address != "10.0.113.7" {
+		t.Errorf("unexpected replayed PPP users: %+v", users)
+	}
+}
+
+// poolFixture has IPv4 pools with ranges across /24 boundaries, an IPv6 prefix
+// pool and a DHCP server handing out addresses of one of the pools.
+var poolFixture = &mikrotik.Fixture{Commands: []mikrotik.FixtureCommand{
+	{Command: "/ip/pool/print", Replies: []map[string]string{
+		{"name": "lan-pool", "ranges": "192.168.88.10-192.168.91.254,192.168.95.0/24", "next-pool": "overflow"},
+		{"name": "overflow", "ranges": "172.16.0.1-172.16.0.100"},
+	}},
+	{Command: "/ipv6/pool/print", Replies: []map[string]string{
+		{"name": "pd", "prefix": "2a02:1234:5600::/40", "prefix-length": "56"},
+	}},
+	{Command: "/ip/pool/used/print", Args: []string{"=count-only=", "?pool=lan-pool"}, Done: map[string]string{"ret": "3"}},
+	{Command: "/ip/pool/used/print", Args: []string{"=count-only=", "?pool=overflow"}, Done: map[string]string{"ret": "0"}},
+	{Command: "/ipv6/pool/used/print", Args: []string{"=count-only=", "?pool=pd"}, Done: map[string]string{"ret": "12"}},
+	{Command: "/ip/dhcp-server/print", Replies: []map[string]string{
+		{"name": "lan", "interface": "bridge", "address-pool": "lan-pool", "lease-time": "10m", "disabled": "false"},
+	}},
+	{Command: "/ip/dhcp-server/lease/print", Args: []string{"=count-only="}, Done: map[string]string{"ret": "0"}},
+	{Command: "/ip/dhcp-server/lease/print", Args: []string{"=count-only=", "?server=lan"}, Done: map[string]string{"ret": "3"}},
+	{Command: "/ip/dhcp-server/lease/print", Args: []string{"=count-only=", "?server=lan", "?status=bound"}, Done: map[string]string{"ret": "3"}},
+	{Command: "/ip/dhcp-server/lease/print", Replies: []map[string]string{
+		{"server": "lan", "address": "192.168.88.10", "mac-address": "48:A9:8A:00:00:01", "host-name": "laptop", "status": "bound", "dynamic": "true", "expires-after": "5m"},
+		{"server": "lan", "address": "192.168.91.254", "mac-address": "48:A9:8A:00:00:02", "host-name": "phone", "status": "bound", "dynamic": "true", "expires-after": "7m"},
+		{"server": "lan", "address": "192.168.95.7", "mac-address": "48:A9:8A:00:00:03", "host-name": "printer", "status": "bound", "dynamic": "false"},
+	}},
+}}
+
+func TestRecordAndReplayPools(t *testing.T) {
+	srv, err := fakeapi.NewServer(poolFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	ctx := context.Background()
+	live := mikrotik.NewClient(srv.Addr(), "prometheus", "secret", 5*time.Second)
+	live.Recorder = mikrotik.NewRecorder(mikrotik.Redaction{MACs: true, IPs: true})
+	defer live.Close()
+
+	livePools, err := live.GetIPPools(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	liveServers, err := live.GetDHCPServers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	liveLeases, err := live.GetDHCPLeases(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(livePools) != 3 || len(liveServers) != 1 || len(liveLeases) != 3 {
+		t.Fatalf("unexpected live data: %+v %+v %+v", livePools, liveServers, liveLeases)
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.yml")
+	if err := live.Recorder.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := mikrotik.LoadFixture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := fixtureText(fixture)
+	for _, secret := range []string{"192.168.", "172.16.", "2a02:1234"} {
+		if strings.Contains(content, secret) {
+			t.Errorf("recorded fixture contains %q", secret)
+		}
+	}
+
+	replay := mikrotik.NewClient("replay", "", "", 5*time.Second)
+	replay.Backend = mikrotik.BackendReplay
+	replay.Fixture = fixture
+
+	// The redacted ranges and prefixes have the size of the original ones.
+	replayPools, err := replay.GetIPPools(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(livePools, replayPools) {
+		t.Errorf("replayed IP pools differ:\n  live: %+v\nreplay: %+v", livePools, replayPools)
+	}
+	replayServers, err := replay.GetDHCPServers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(liveServers, replayServers) {
+		t.Errorf("replayed DHCP servers differ:\n  live: %+v\nreplay: %+v", liveServers, replayServers)
+	}
+
+	// Leases keep their offsets within the network of the pool.
+	replayLeases, err := replay.GetDHCPLeases(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var addresses []string
+	for _, lease := range replayLeases {
+		addresses = append(addresses, lease.Address)
+	}
+	if want := []string{"10.0.88.10", "10.0.91.254", "10.0.95.7"}; !reflect.DeepEqual(addresses, want) {
+		t.Errorf("replayed lease addresses = %q, want %q", addresses, want)
+	}
+}
+
+func TestParseRedaction(t *testing.T) {
+	r, err := mikrotik.ParseRedaction("serials, ips")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (mikrotik.Redaction{Serials: true, IPs: true}); r != want {
+		t.Errorf("got %+v, want %+v", r, want)
+	}
+	if _, err := mikrotik.ParseRedaction("hostnames"); err == nil {
+		t.Error("expected error for unknown redaction")
+	}
+}
+
+func fixtureText(f *mikrotik.Fixture) string {
+	var b strings.Builder
+	for _, cmd := range f.Commands {
+		b.WriteString(strings.Join(cmd.Args, " "))
+		for _, re := range cmd.Replies {
+			for k, v := range re {
+				b.WriteString(k + "=" + v + "\n")
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package mikrotik
+
+import (
+	"context"
+	"errors"
+	"log"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/proto"
+)
+
+// runReplay answers a command from the fixture of the client. Commands missing
+// from the fixture fail like on a router without the corresponding menu.
+func (c *Client) runReplay(ctx context.Context, args []string) (*routeros.Reply, error) {
+	path := commandPath(args)
+	if err := ctx.Err(); err != nil {
+		return nil, c.abortError(path, err)
+	}
+	if c.Fixture == nil {
+		return nil, errors.New("replay backend without fixture")
+	}
+
+	cmd, ok := c.Fixture.Lookup(args)
+	if !ok {
+		return nil, trapError("no such command")
+	}
+	if cmd.Delay > 0 {
+		select {
+		case <-time.After(cmd.Delay):
+		case <-ctx.Done():
+			return nil, c.abortError(path, ctx.Err())
+		}
+	}
+	if cmd.Trap != "" {
+		err := trapError(cmd.Trap)
+		log.Printf("Error running command %v on %s: %v", args, c.Address, err)
+		return nil, err
+	}
+
+	reply := &routeros.Reply{Done: newSentence("!done", cmd.Done)}
+	for _, re := range cmd.Replies {
+		reply.Re = append(reply.Re, newSentence("!re", re))
+	}
+	return reply, nil
+}
+
+// trapError returns the error of a !trap reply with the given message.
+func trapError(message string) error {
+	return &routeros.DeviceError{Sentence: newSentence("!trap", map[string]string{"message": message})}
+}
+
+// newSentence builds a sentence with the given attributes in sorted order.
+func newSentence(word string, attrs map[string]string) *proto.Sentence {
+	sentence := &proto.Sentence{Word: word, Map: make(map[string]string, len(attrs))}
+	for _, key := range slices.Sorted(maps.Keys(attrs)) {
+		sentence.Map[key] = attrs[key]
+		sentence.List = append(sentence.List, proto.Pair{Key: key, Value: attrs[key]})
+	}
+	return sentence
+}
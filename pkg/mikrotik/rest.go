@@ -0,0 +1,283 @@
+package mikrotik
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-routeros/routeros/v3"
+)
+
+const defaultRESTPort = "80"
+const defaultRESTSSLPort = "443"
+
+// restTransport runs commands through the /rest endpoints of a router.
+type restTransport struct {
+	http    *http.Client
+	baseURL string
+}
+
+// restError is the body of a failed REST request.
+type restError struct {
+	Error   int    `json:"error"`
+	Message string `json:"message"`
+	Detail  string `json:"detail"`
+}
+
+// connectRESTLocked prepares the HTTP client and checks the credentials with
+// a cheap request, so that an unreachable router or a failed login is
+// reported by Connect just like with the binary API.
+func (c *Client) connectRESTLocked(ctx context.Context) error {
+	addr := c.Address
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		port := defaultRESTPort
+		if c.TLS.Enabled {
+			port = defaultRESTSSLPort
+		}
+		addr = net.JoinHostPort(addr, port)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	scheme := "http"
+	if c.TLS.Enabled {
+		tlsConfig, err := newTLSConfig(c.TLS)
+		if err != nil {
+			log.Printf("Error preparing TLS configuration for MikroTik router %s: %v", addr, err)
+			return err
+		}
+		transport.TLSClientConfig = tlsConfig
+		scheme = "https"
+	}
+	rest := &restTransport{
+		http:    &http.Client{Transport: transport},
+		baseURL: scheme + "://" + addr + "/rest",
+	}
+
+	log.Printf("Connecting to MikroTik router REST API at %s with timeout %s...", rest.baseURL, c.Timeout)
+	checkCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+	if _, err := c.restDo(checkCtx, rest, []string{"/system/identity/print"}); err != nil {
+		transport.CloseIdleConnections()
+		dialFailures.Inc()
+		log.Printf("Error connecting to MikroTik router REST API at %s: %v", rest.baseURL, err)
+		if ctxErr := checkCtx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+
+	c.closeLocked()
+	c.rest = rest
+	return nil
+}
+
+// restSession returns the REST transport, preparing it if needed, and the
+// semaphore bounding the number of requests in flight.
+func (c *Client) restSession(ctx context.Context) (*restTransport, chan struct{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sem == nil {
+		c.sem = make(chan struct{}, max(c.MaxConcurrency, 1))
+	}
+	if c.rest == nil {
+		if err := c.connectRESTLocked(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	return c.rest, c.sem, nil
+}
+
+// runREST is the REST counterpart of runOnce.
+func (c *Client) runREST(ctx context.Context, args []string) (*routeros.Reply, error) {
+	path := commandPath(args)
+	if err := ctx.Err(); err != nil {
+		return nil, c.abortError(path, err)
+	}
+
+	rest, sem, err := c.restSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, c.abortError(path, ctx.Err())
+	}
+	defer func() { <-sem }()
+
+	cmdCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	reply, err := c.restDo(cmdCtx, rest, args)
+	if cmdErr := cmdCtx.Err(); cmdErr != nil {
+		return nil, c.abortError(path, cmdErr)
+	}
+	if err != nil {
+		log.Printf("Error running command %v on %s: %v", args, c.Address, err)
+		return nil, err
+	}
+	return reply, nil
+}
+
+// restDo sends a command as POST request to the menu path of the command and
+// converts the JSON response into the reply the binary API would return.
+func (c *Client) restDo(ctx context.Context, rest *restTransport, args []string) (*routeros.Reply, error) {
+	if len(args) == 0 {
+		return nil, errors.New("empty command")
+	}
+	body, err := json.Marshal(restRequestBody(args[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rest.baseURL+args[0], bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err := rest.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, restStatusError(resp.StatusCode, content)
+	}
+	return restReply(content)
+}
+
+// restRequestBody translates the words of a binary API command into the JSON
+// body of a REST request. Attribute words (=name=value, or name=value as used
+// by some commands) become properties, e.g. "=count-only=" becomes
+// "count-only": "". Query words (?...) go to .query and bare flags like
+// "stats" or "once" become empty properties. Options that only matter to the
+// console, such as "without-paging", are dropped.
+func restRequestBody(words []string) map[string]any {
+	body := make(map[string]any)
+	var query []string
+	for _, word := range words {
+		switch {
+		case strings.HasPrefix(word, "?"):
+			query = append(query, word[1:])
+		case strings.HasPrefix(word, "="):
+			name, value, _ := strings.Cut(word[1:], "=")
+			if name == ".proplist" {
+				body[name] = strings.Split(value, ",")
+				continue
+			}
+			body[name] = value
+		case word == "without-paging" || word == "detail":
+		default:
+			name, value, _ := strings.Cut(word, "=")
+			body[name] = value
+		}
+	}
+	if len(query) > 0 {
+		body[".query"] = query
+	}
+	return body
+}
+
+// restReply converts a REST response into a reply. A list becomes one !re
+// sentence per item, a single object one !re sentence, and the "ret" object
+// of commands such as count-only prints becomes the attribute of !done.
+func restReply(content []byte) (*routeros.Reply, error) {
+	reply := &routeros.Reply{Done: newSentence("!done", nil)}
+
+	content = bytes.TrimSpace(content)
+	if len(content) == 0 {
+		return reply, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	var data any
+	if err := dec.Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode REST response: %w", err)
+	}
+
+	var items []any
+	switch v := data.(type) {
+	case []any:
+		items = v
+	case map[string]any:
+		if ret, ok := v["ret"]; ok && len(v) == 1 {
+			reply.Done = newSentence("!done", map[string]string{"ret": restValue(ret)})
+			return reply, nil
+		}
+		items = []any{v}
+	default:
+		return nil, fmt.Errorf("unexpected REST response: %s", content)
+	}
+
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unexpected REST response item: %v", item)
+		}
+		attrs := make(map[string]string, len(obj))
+		for key, value := range obj {
+			attrs[key] = restValue(value)
+		}
+		reply.Re = append(reply.Re, newSentence("!re", attrs))
+	}
+	return reply, nil
+}
+
+// restValue formats a JSON value the way the binary API would send it.
+func restValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// restStatusError turns a failed REST request into an error. Errors reported
+// by RouterOS itself are returned as *routeros.DeviceError carrying the
+// detail message, so that callers can handle them like a !trap reply.
+func restStatusError(status int, content []byte) error {
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return fmt.Errorf("REST request rejected: %s", http.StatusText(status))
+	}
+
+	var restErr restError
+	if err := json.Unmarshal(content, &restErr); err != nil || (restErr.Message == "" && restErr.Detail == "") {
+		if status == http.StatusNotFound {
+			restErr.Detail = "no such command"
+		} else {
+			return fmt.Errorf("REST request failed: %s", http.StatusText(status))
+		}
+	}
+
+	message := restErr.Detail
+	if message == "" {
+		message = restErr.Message
+	}
+	return trapError(message)
+}
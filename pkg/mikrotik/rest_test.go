@@ -0,0 +1,152 @@
+package mikrotik
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newRESTStandIn starts an HTTP server answering REST requests for the given
+// menu paths. Requests for other paths fail like on a router without the
+// corresponding package.
+func newRESTStandIn(t *testing.T, handlers map[string]func(body map[string]any) any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "prometheus" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request body for %s: %v", r.URL.Path, err)
+		}
+
+		handler, ok := handlers[strings.TrimPrefix(r.URL.Path, "/rest")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(restError{Error: 400, Message: "Bad Request", Detail: "no such command or directory (print)"})
+			return
+		}
+		json.NewEncoder(w).Encode(handler(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newRESTClient(srv *httptest.Server, password string) *Client {
+	c := NewClient(strings.TrimPrefix(srv.URL, "http://"), "prometheus", password, 5*time.Second)
+	c.Backend = BackendREST
+	return c
+}
+
+var identityHandler = func(map[string]any) any {
+	return map[string]any{"name": "router"}
+}
+
+func TestRESTInterfaceStats(t *testing.T) {
+	srv := newRESTStandIn(t, map[string]func(map[string]any) any{
+		"/system/identity/print": identityHandler,
+		"/interface/print": func(body map[string]any) any {
+			if _, ok := body["stats"]; ok {
+				return []map[string]any{
+					{".id": "*1", "name": "ether1", "rx-byte": "1000", "tx-byte": "2000", "rx-packet": "10", "tx-packet": "20"},
+				}
+			}
+			if proplist, ok := body[".proplist"]; ok {
+				if got := proplist.([]any); len(got) != 2 || got[0] != "name" || got[1] != "type" {
+					t.Errorf(".proplist = %v, want [name type]", got)
+				}
+			}
+			return []map[string]any{
+				{".id": "*1", "name": "ether1", "type": "ether", "running": "true", "disabled": "false", "mac-address": "00:11:22:33:44:55"},
+			}
+		},
+	})
+
+	c := newRESTClient(srv, "secret")
+	defer c.Close()
+
+	stats, err := c.GetInterfaceStats(context.Background())
+	if err != nil {
+		t.Fatalf("GetInterfaceStats: %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("got %d interfaces, want 1", len(stats))
+	}
+	got := stats[0]
+	if got.Name != "ether1" || !got.Running || got.Disabled || got.MACAddress != "00:11:22:33:44:55" {
+		t.Errorf("unexpected interface details: %+v", got)
+	}
+	if got.RxBytes != 1000 || got.TxBytes != 2000 || got.RxPackets != 10 || got.TxPackets != 20 {
+		t.Errorf("unexpected interface counters: %+v", got)
+	}
+}
+
+func TestRESTMissingCommand(t *testing.T) {
+	srv := newRESTStandIn(t, map[string]func(map[string]any) any{
+		"/system/identity/print": identityHandler,
+	})
+
+	c := newRESTClient(srv, "secret")
+	defer c.Close()
+
+	stats, err := c.GetBGPPeerStats(context.Background())
+	if err != nil {
+		t.Fatalf("GetBGPPeerStats: %v", err)
+	}
+	if len(stats) != 0 {
+		t.Errorf("got %d BGP peers, want none", len(stats))
+	}
+}
+
+func TestRESTCountOnly(t *testing.T) {
+	srv := newRESTStandIn(t, map[string]func(map[string]any) any{
+		"/system/identity/print": identityHandler,
+		"/ip/route/print": func(body map[string]any) any {
+			if v, ok := body["count-only"]; !ok || v != "" {
+				t.Errorf("count-only = %v, want empty property in request body %v", v, body)
+			}
+			if query, _ := body[".query"].([]any); len(query) != 1 || query[0] != "active=true" {
+				t.Errorf(".query = %v, want [active=true]", body[".query"])
+			}
+			return map[string]any{"ret": "42"}
+		},
+	})
+
+	c := newRESTClient(srv, "secret")
+	defer c.Close()
+
+	reply, err := c.Run(context.Background(), "/ip/route/print", "=count-only=", "?active=true")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := reply.Done.Map["ret"]; got != "42" {
+		t.Errorf("ret = %q, want 42", got)
+	}
+}
+
+func TestRESTConnectRejectsBadCredentials(t *testing.T) {
+	srv := newRESTStandIn(t, map[string]func(map[string]any) any{
+		"/system/identity/print": identityHandler,
+	})
+
+	c := newRESTClient(srv, "wrong")
+	defer c.Close()
+
+	if err := c.Connect(context.Background()); err == nil {
+		t.Fatal("Connect succeeded with wrong password")
+	}
+	if c.Connected() {
+		t.Error("client reports a connection after a failed login")
+	}
+}
@@ -0,0 +1,172 @@
+package mikrotik
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// routeProtocols are the protocols counted separately by GetRouteCounts, by
+// label and route flag.
+var routeProtocols = []struct {
+	label string
+	flag  string
+}{
+	{"connected", "connect"},
+	{"static", "static"},
+	{"bgp", "bgp"},
+	{"ospf", "ospf"},
+	{"dhcp", "dhcp"},
+}
+
+// RouteCount is the number of routes of an address family and routing table,
+// either of one protocol or, if Protocol is empty, of all protocols.
+type RouteCount struct {
+	AddressFamily string
+	Table         string
+	Protocol      string
+	Active        uint64
+	Inactive      uint64
+}
+
+// routeMenus are the route menus by address family label.
+var routeMenus = []struct {
+	afi  string
+	menu string
+}{
+	{"ipv4", "/ip/route/print"},
+	{"ipv6", "/ipv6/route/print"},
+}
+
+// GetRouteCounts counts the routes in /ip/route and /ipv6/route by routing
+// table, protocol and active flag. The routes are only counted with
+// count-only queries, so the cost does not grow with the size of the routing
+// table.
+//
+// RouterOS 6 has no routing tables, only routing marks: there the routes
+// without a mark are counted as table "main" and the routes of each routing
+// mark as a table named after the mark.
+func (c *Client) GetRouteCounts(ctx context.Context) ([]RouteCount, error) {
+	var counts []RouteCount
+	for _, family := range routeMenus {
+		tables, err := c.getRoutingTables(ctx, family.menu)
+		if err == nil {
+			var familyCounts []RouteCount
+			familyCounts, err = c.countRoutes(ctx, family.afi, family.menu, tables)
+			counts = append(counts, familyCounts...)
+		}
+		if err != nil {
+			if strings.Contains(err.Error(), "no such command") {
+				log.Printf("No %s menu on %s. Skipping %s route metrics.", family.menu, c.Address, family.afi)
+				continue
+			}
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+// getRoutingTables returns the query selecting the routes of each routing
+// table by table name.
+func (c *Client) getRoutingTables(ctx context.Context, menu string) (map[string]string, error) {
+	caps := c.capabilities(ctx)
+	if caps.Major != 0 && !caps.IsV7() {
+		return c.getRoutingMarks(ctx, menu)
+	}
+
+	reply, err := c.Run(ctx, "/routing/table/print", "without-paging", "=.proplist=name")
+	if err != nil {
+		if caps.Major == 0 && strings.Contains(err.Error(), "no such command") {
+			return c.getRoutingMarks(ctx, menu)
+		}
+		return nil, fmt.Errorf("failed to get routing tables: %w", err)
+	}
+	tables := map[string]string{"main": "?routing-table=main"}
+	for _, re := range reply.Re {
+		if name := re.Map["name"]; name != "" {
+			tables[name] = "?routing-table=" + name
+		}
+	}
+	return tables, nil
+}
+
+// routingMarkSources are the commands listing the routing marks of RouterOS 6
+// per route menu, and the attributes holding the marks: the routing rules,
+// the mangle rules marking routing and the VRFs. IPv6 routes can only be
+// marked by mangle rules.
+var routingMarkSources = map[string][]struct {
+	args  []string
+	attrs []string
+}{
+	"/ip/route/print": {
+		{[]string{"/ip/route/rule/print", "=.proplist=routing-mark,table"}, []string{"routing-mark", "table"}},
+		{[]string{"/ip/firewall/mangle/print", "?action=mark-routing", "=.proplist=new-routing-mark"}, []string{"new-routing-mark"}},
+		{[]string{"/ip/route/vrf/print", "=.proplist=routing-mark"}, []string{"routing-mark"}},
+	},
+	"/ipv6/route/print": {
+		{[]string{"/ipv6/firewall/mangle/print", "?action=mark-routing", "=.proplist=new-routing-mark"}, []string{"new-routing-mark"}},
+	},
+}
+
+// getRoutingMarks returns the queries selecting the routes of each routing
+// mark of RouterOS 6, and of the routes without a mark as "main". The marks
+// are taken from the configuration that uses them, see routingMarkSources,
+// as listing the marked routes themselves would grow with the table size.
+func (c *Client) getRoutingMarks(ctx context.Context, menu string) (map[string]string, error) {
+	tables := map[string]string{"main": "?-routing-mark"}
+	for _, source := range routingMarkSources[menu] {
+		reply, err := c.RunArgs(ctx, append([]string{source.args[0], "without-paging"}, source.args[1:]...))
+		if err != nil {
+			if strings.Contains(err.Error(), "no such command") {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get routing marks from %s: %w", source.args[0], err)
+		}
+		for _, re := range reply.Re {
+			for _, attr := range source.attrs {
+				if mark := re.Map[attr]; mark != "" && mark != "main" {
+					tables[mark] = "?routing-mark=" + mark
+				}
+			}
+		}
+	}
+	return tables, nil
+}
+
+func (c *Client) countRoutes(ctx context.Context, afi, menu string, tables map[string]string) ([]RouteCount, error) {
+	var counts []RouteCount
+	for _, table := range slices.Sorted(maps.Keys(tables)) {
+		tableQuery := tables[table]
+		// The first count covers all protocols.
+		for i := -1; i < len(routeProtocols); i++ {
+			queries := []string{tableQuery}
+			protocol := ""
+			if i >= 0 {
+				protocol = routeProtocols[i].label
+				queries = append(queries, "?"+routeProtocols[i].flag+"=true")
+			}
+			total, err := c.count(ctx, menu, queries...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to count %s routes in table %s: %w", afi, table, err)
+			}
+			active := uint64(0)
+			if total > 0 {
+				active, err = c.count(ctx, menu, append(queries, "?active=true")...)
+				if err != nil {
+					return nil, fmt.Errorf("failed to count active %s routes in table %s: %w", afi, table, err)
+				}
+			}
+			counts = append(counts, RouteCount{
+				AddressFamily: afi,
+				Table:         table,
+				Protocol:      protocol,
+				Active:        active,
+				Inactive:      total - min(active, total),
+			})
+		}
+	}
+	return counts, nil
+}
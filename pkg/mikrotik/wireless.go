@@ -1,6 +1,7 @@
 package mikrotik
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strconv"
@@ -28,8 +29,17 @@ type WirelessInterface struct {
 	RxRate         float64
 }
 
-func (c *Client) FetchWirelessClients() ([]WirelessClient, error) {
-	reply, err := c.Run("/interface/wireless/registration-table/print", "=.proplist=interface,mac-address,signal-strength,tx-ccq,rx-rate,tx-rate,uptime")
+// FetchWirelessClients fetches the registration table of the wireless package
+// detected on the router.
+func (c *Client) FetchWirelessClients(ctx context.Context) ([]WirelessClient, error) {
+	menu := c.capabilities(ctx).WirelessMenu()
+	if menu == "" {
+		log.Printf("No wireless package enabled on %s, skipping wireless client metrics.", c.Address)
+		return nil, nil
+	}
+
+	// The wifi and wifiwave2 packages report the signal as "signal" and have no CCQ.
+	reply, err := c.Run(ctx, menu+"/registration-table/print", "=.proplist=interface,mac-address,signal-strength,signal,tx-ccq,rx-rate,tx-rate,uptime")
 	if err != nil {
 		if strings.Contains(err.Error(), "no such command") || strings.Contains(err.Error(), "disabled") {
 			log.Println("Wireless package might be disabled or not installed, skipping wireless client metrics.")
@@ -46,8 +56,11 @@ func (c *Client) FetchWirelessClients() ([]WirelessClient, error) {
 			continue
 		}
 
-		signalStr := strings.Split(re.Map["signal-strength"], "@")[0]
-		signal, _ := strconv.Atoi(signalStr)
+		signalStr := re.Map["signal-strength"]
+		if signalStr == "" {
+			signalStr = re.Map["signal"]
+		}
+		signal, _ := strconv.Atoi(strings.Split(signalStr, "@")[0])
 
 		ccqStr := re.Map["tx-ccq"]
 		ccq, _ := strconv.Atoi(ccqStr)
@@ -67,8 +80,17 @@ func (c *Client) FetchWirelessClients() ([]WirelessClient, error) {
 	return clients, nil
 }
 
-func (c *Client) FetchWirelessInterfaces() ([]WirelessInterface, error) {
-	ifListReply, err := c.Run("/interface/wireless/print", "=.proplist=.id,name")
+// FetchWirelessInterfaces monitors every interface of the wireless package
+// detected on the router.
+func (c *Client) FetchWirelessInterfaces(ctx context.Context) ([]WirelessInterface, error) {
+	menu := c.capabilities(ctx).WirelessMenu()
+	if menu == "" {
+		log.Printf("No wireless package enabled on %s, skipping wireless interface metrics.", c.Address)
+		return nil, nil
+	}
+
+	// The wifi and wifiwave2 packages keep the SSID in configuration.ssid.
+	ifListReply, err := c.Run(ctx, menu+"/print", "=.proplist=.id,name,ssid,configuration.ssid")
 	if err != nil {
 		if strings.Contains(err.Error(), "no such command") || strings.Contains(err.Error(), "disabled") {
 			log.Println("Wireless package might be disabled or not installed, skipping wireless interface metrics.")
@@ -86,12 +108,12 @@ func (c *Client) FetchWirelessInterfaces() ([]WirelessInterface, error) {
 			continue
 		}
 
-		monitorReply, err := c.RunArgs(
+		monitorReply, err := c.RunArgs(ctx,
 			[]string{
-				"/interface/wireless/monitor",
+				menu + "/monitor",
 				fmt.Sprintf("=numbers=%s", ifaceID),
 				"=once=",
-				"=.proplist=name,ssid,frequency,signal-strength,rate-set,tx-rate,rx-rate",
+				"=.proplist=name,ssid,frequency,channel,signal-strength,rate-set,tx-rate,rx-rate",
 			},
 		)
 
@@ -104,6 +126,10 @@ func (c *Client) FetchWirelessInterfaces() ([]WirelessInterface, error) {
 			monData := monitorReply.Re[0].Map
 
 			freqStr := monData["frequency"]
+			if freqStr == "" {
+				// wifi and wifiwave2 report e.g. channel=5180/ax/Ceee.
+				freqStr, _, _ = strings.Cut(monData["channel"], "/")
+			}
 			freq, _ := strconv.Atoi(freqStr)
 
 			signalStr := strings.Split(monData["signal-strength"], "@")[0]
@@ -115,9 +141,17 @@ func (c *Client) FetchWirelessInterfaces() ([]WirelessInterface, error) {
 			rxRateStr := monData["rx-rate"]
 			rxRate, _ := strconv.ParseFloat(rxRateStr, 64)
 
+			ssid := monData["ssid"]
+			if ssid == "" {
+				ssid = ifaceEntry.Map["ssid"]
+			}
+			if ssid == "" {
+				ssid = ifaceEntry.Map["configuration.ssid"]
+			}
+
 			iface := WirelessInterface{
 				Name:           ifaceName,
-				SSID:           monData["ssid"],
+				SSID:           ssid,
 				Frequency:      freq,
 				SignalStrength: signal,
 				TxRate:         txRate,
@@ -127,5 +161,9 @@ func (c *Client) FetchWirelessInterfaces() ([]WirelessInterface, error) {
 		}
 	}
 
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("error monitoring wireless interfaces: %w", ctx.Err())
+	}
+
 	return interfaces, nil
 }